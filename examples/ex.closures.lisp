@@ -0,0 +1,30 @@
+;; lambdas are values which capture variables of the enclosing scope
+(def make-adder (n:int) :func[int,int]
+  (lambda (x:int) :int (+ x n)))
+
+(set add5 (make-adder 5))
+(set add10 (make-adder 10))
+(print (add5 1) (add10 1) (type add5))
+
+;; closures created in recursive calls keep their own values
+(def make-adders (n:int acc:list) :list
+  (if (= n 0)
+    acc
+    (make-adders (- n 1) (append acc (lambda (+ _1 n))))))
+
+(def call-all ('() x:int res:list) :list res)
+(def call-all ((f . fs) x:int res:list) :list (call-all fs x (append res (f x))))
+
+(print (call-all (make-adders 3 '()) 100 '()))
+
+;; closures returning closures
+(set curry-mul (lambda (a:int) :func (lambda (b:int) :int (* a b))))
+(set times3 (curry-mul 3))
+(print (times3 7))
+
+;; lambdas can be used in lazy lists
+(def take-n (0 l:list acc:list) :list acc)
+(def take-n (n:int l:list acc:list) :list (take-n (- n 1) (native.tail l) (append acc (native.head l))))
+
+(set step 3)
+(print (take-n 5 (gen (lambda (list _1 (+ _1 step))) 0) '()))
@@ -0,0 +1,36 @@
+(def grade (score:int) :str
+  (cond
+    ((>= score 90) "A")
+    ((>= score 75) "B")
+    ((>= score 50) "C")
+    (else "F")))
+
+(print (grade 95) (grade 80) (grade 60) (grade 10))
+
+(def describe (x:any) :str
+  (case x
+    (0 "zero")
+    ("" "empty string")
+    ('() "empty list")
+    ((h) "list of one element")
+    ((h . t) "long list")
+    (else "something else")))
+
+(print (describe 0) (describe "") (describe '()) (describe '(1)) (describe '(1 2 3)) (describe 5))
+
+;; variables bound by patterns are visible only in the clause body
+(def sum (l:list[int] acc:int) :int
+  (case l
+    ('() acc)
+    ((h . t) (sum t (+ acc h)))))
+
+(print (sum (do '(1 2 3 4 5) :list[int]) 0))
+
+(defunion :shape (:circle r:int) (:rect w:int h:int))
+
+(def area (s:shape) :int
+  (case s
+    ((:circle r) (* 3 r r))
+    ((:rect w h) (* w h))))
+
+(print (area (circle 2)) (area (rect 3 4)))
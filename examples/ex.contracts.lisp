@@ -0,0 +1,21 @@
+; :ord is defined in builtin library as
+; (contract :ord (< :ord :ord) :bool)
+(def max2 (a:ord b:ord) :ord (if (< a b) b a))
+(def max3 (a:ord b:ord c:ord) :ord (max2 a (max2 b c)))
+
+(print (max2 3 5))
+(print (max3 "abc" "abd" "ab"))
+(print (max2 1.5 0.5))
+
+(defrecord :rect (w:int h:int))
+
+(contract :shape (area :shape) :int)
+
+(def area (r:rect) :int (* (rect-w r) (rect-h r)))
+(def area (n:int) :int (* n n))
+
+(def bigger (a:shape b:shape) :shape (if (< (area a) (area b)) b a))
+
+(print (bigger (make-rect 2 3) (make-rect 1 5)))
+(print (bigger 2 3))
+; (print (bigger "a" "b")) ; type :str does not satisfy contract :shape
@@ -0,0 +1,25 @@
+; floating-point numbers
+(use std)
+
+(print 1.5 -0.25 3.0 1e3)
+
+; arithmetic with floats returns floats, integers are promoted
+(print (+ 1.5 2.25))
+(print (* 2 1.5))
+(print (/ 7 2) (/ 7.0 2))
+(print (- 10 0.5 0.25))
+
+(def average (l:list) :float
+	 (/ (float (do (reduce + l 0) :int)) (length l)))
+
+(print (average '(1 2 3 4)))
+
+; comparisons
+(print (< 1.5 2.5) (> 1.5 2.5) (<= 2.0 2.0) (>= 1 2.5))
+
+; conversions
+(print (int 3.99) (int -3.99) (float 2) (float "2.75") (str 0.5) (str 42))
+(print (type 1.0) (type (+ 1 2)) (type (+ 1 2.0)))
+
+(def half (x:float) :float (/ x 2))
+(print (half 5))
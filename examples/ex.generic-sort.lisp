@@ -1,16 +1,16 @@
-(def partition (l:list[a] pivot:a) :list (partition l pivot (do '() :list[a]) (do '() :list[a])))
+(def partition (l:list[a] pivot:a) :list (partition l pivot '() '()))
 (def partition ('() pivot:a lo:list[a] hi:list[a]) :list[any] (list lo hi))
 
 (def partition (l:list[a] pivot:a lo:list[a] hi:list[a]) :list[any]
 	 (set h (head l))
 	 (set t (tail l))
 	 (if (< h pivot)
-	   (partition t pivot (do (append lo h) :list[a]) hi)
-	   (partition t pivot lo (do (append hi h) :list[a]))) :list[any])
+	   (partition t pivot (append lo h) hi)
+	   (partition t pivot lo (append hi h))))
 
 (def conc (l1:list[a] '()) :list[a] l1)
 (def conc (l1:list[a] l2:list[a]) :list[a]
-	 (conc (do (append l1 (head l2)) :list[a]) (tail l2)))
+	 (conc (append l1 (head l2)) (tail l2)))
 
 (def len ('()) :int 0)
 (def len (l:list[a]) :int (+ 1 (len (tail l))))
@@ -26,10 +26,10 @@
 		 (set parts (partition (tail l) (head l)))
 		 (set lo (fst parts) :list[a])
 		 (set hi (snd parts) :list[a])
-		 (conc (do (append (sort lo) (head l)) :list[a]) (sort hi)))) :list[a])
+		 (conc (append (sort lo) (head l)) (sort hi)))))
 
 
-(set l '(5 13 2 8 3 1) :list[int])
+(set l '(5 13 2 8 3 1))
 (print (partition l 4))
 
 (print (sort l))
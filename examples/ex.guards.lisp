@@ -0,0 +1,34 @@
+(def sign (n:int) :str when (< n 0) "negative")
+(def sign (0) :str "zero")
+(def sign (n:int) :str "positive")
+
+(print (sign -5) (sign 0) (sign 7))
+
+;; guards can use all arguments and any functions
+(def classify (a:int b:int) :str when (= a b) "equal")
+(def classify (a:int b:int) :str when (< a b) "less")
+(def classify (a:int b:int) :str "greater")
+
+(print (classify 1 1) (classify 1 2) (classify 2 1))
+
+;; guards work with tail calls and patterns
+(def count-positive ('() acc:int) :int acc)
+(def count-positive ((h . t) acc:int) :int when (> h 0) (count-positive t (+ acc 1)))
+(def count-positive ((h . t) acc:int) :int (count-positive t acc))
+
+(print (count-positive '(1 -2 3 0 5 -6) 0))
+
+(defunion :result (:ok value:int) (:err message:str))
+
+(def check ((:ok v)) :str when (> v 100) "too big")
+(def check ((:ok v)) :str "fine")
+(def check ((:err m)) :str m)
+
+(print (check (ok 500)) (check (ok 5)) (check (err "failed")))
+
+;; collatz
+(def collatz (1 steps:int) :int steps)
+(def collatz (n:int steps:int) :int when (= (mod n 2) 0) (collatz (/ n 2) (+ steps 1)))
+(def collatz (n:int steps:int) :int (collatz (+ (* 3 n) 1) (+ steps 1)))
+
+(print (collatz 27 0))
@@ -0,0 +1,42 @@
+(set m (map-of "one" 1 "two" 2 "three" 3))
+(print m)
+(print (type m))
+(print (map-get m "two"))
+(print (map-get m "four" 0))
+(print (map-contains m "one") (map-contains m "four"))
+(print (map-size m))
+
+(set m2 (map-put m "four" 4))
+(print (map-size m) (map-size m2))
+(print (map-get m2 "four"))
+(print (map-remove m2 "one"))
+(print (= (map-remove (map-put m "x" 10) "x") m))
+(print (= (map-of 1 "a" 2 "b") (map-put (map-of 2 "b") 1 "a")))
+
+(def sum (l:list[int]) :int
+	 (if (empty l) 0 (+ (head l) (sum (tail l)))))
+
+(print (sum (map-values m)))
+(print (map-keys (map-of 1 true)))
+(print (map-items (map-of "a" 1)))
+
+;; counting letters
+(def count-letters (s:list[str] acc:map[str,int]) :map[str,int]
+	 (if (empty s)
+	   acc
+	   (count-letters
+		 (tail s)
+		 (map-put acc (head s) (+ 1 (map-get acc (head s) 0))))))
+
+(set counts (count-letters "abacab" (do (map-of) :map[str,int])))
+(print (map-get counts "a") (map-get counts "b") (map-get counts "c"))
+
+;; memoization with map arguments
+(def' lookup (m:map[str,int] k:str) :int
+	(print "evaluating lookup" k)
+	(map-get m k))
+
+(print (lookup counts "a"))
+(print (lookup (count-letters "baacba" (do (map-of) :map[str,int])) "a"))
+
+(print (try (map-get m "five") (catch e (error-message e))))
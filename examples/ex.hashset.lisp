@@ -0,0 +1,35 @@
+(set s (set-of 1 2 3 2 1))
+(print (type s))
+(print (set-size s))
+(print (set-contains s 2) (set-contains s 5))
+
+(set s2 (set-insert s 5))
+(print (set-size s) (set-size s2))
+(print (= (set-remove s2 1) (set-of 2 3 5)))
+
+(set a (set-of 1 2 3 4))
+(set b (set-of 3 4 5 6))
+(print (= (set-union a b) (set-of 1 2 3 4 5 6)))
+(print (= (set-intersection a b) (set-of 3 4)))
+(print (= (set-difference a b) (set-of 1 2)))
+(print (= (set-of 1 2 3) (set-of 3 2 1)))
+(print (= (set-union a b) (list->set '(6 5 4 3 2 1))))
+
+(def sum (l:list[int]) :int
+	 (if (empty l) 0 (+ (head l) (sum (tail l)))))
+
+(print (sum (set-items s)))
+
+(set colors (set-of "red" "green"))
+(print (set-insert colors "blue"))
+(print (set-insert colors "red"))
+
+;; unique letters
+(def unique (s:list[str] acc:set[str]) :set[str]
+	 (if (empty s)
+	   acc
+	   (unique (tail s) (set-insert acc (head s)))))
+
+(print (set-size (unique "hello world" (do (set-of) :set[str]))))
+(print (type (list->set "abc")))
+(print (set-size (set-of '(1 2) '(1 2) "x")))
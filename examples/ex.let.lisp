@@ -0,0 +1,33 @@
+(def hypot2 (a:int b:int) :int
+  (let ((aa (* a a))
+        (bb (* b b)))
+    (+ aa bb)))
+
+(print (hypot2 3 4))
+
+;; bindings are visible only in the body of let
+(set x 1)
+(print (let ((x 10) (y x)) (+ x y)) x)
+
+;; let* binds variables one by one
+(print (let* ((x 10) (y (* x 2))) (+ x y)))
+
+;; typed bindings
+(print (let ((n 5 :int)) (* n n)))
+
+;; let in tail position does not break tail call optimization
+(def sum-to (n:int acc:int) :int
+  (if (= n 0)
+    acc
+    (let ((next (- n 1))
+          (acc2 (+ acc n)))
+      (sum-to next acc2))))
+
+(print (sum-to 10000 0))
+
+;; nested let shadows outer bindings
+(def nested (a:int) :int
+  (let ((b (+ a 1)))
+    (+ (let ((b (* b 10))) b) b)))
+
+(print (nested 1))
@@ -0,0 +1,57 @@
+(use std)
+
+;; implementations should match both '() and (h . t), otherwise a warning is reported
+
+(def sum ('()) :int 0)
+(def sum ((h . t)) :int (+ h (sum t)))
+
+(print (sum '(1 2 3 4 5)))
+
+(def describe ('()) :str "empty")
+(def describe ((x)) :str "one element")
+(def describe ((x y)) :str "two elements")
+(def describe ((x y . rest)) :str "many elements")
+
+(print (describe '()) (describe '(1)) (describe '(1 2)) (describe '(1 2 3)))
+
+;; literals and repeated variables in patterns
+(def starts-with-zero ((0 . t)) :bool true)
+(def starts-with-zero (l) :bool false)
+
+(def pair-equal ((x x)) :bool true)
+(def pair-equal (l) :bool false)
+
+(print (starts-with-zero '(0 1)) (starts-with-zero '(1 0)))
+(print (pair-equal '(3 3)) (pair-equal '(3 4)))
+
+;; nested patterns
+(def keys ('()) :list '())
+(def keys (((k v) . rest)) :list (concat (list k) (keys rest)))
+(def keys ((x . rest)) :list (keys rest))
+
+(print (keys '(("a" 1) ("b" 2) ("c" 3))))
+
+;; strings are lists too
+(def first-char ((c . rest:str)) :str c)
+(def first-char ('()) :str "")
+(print (first-char "hello"))
+
+;; element type flows from the type of the tail
+(def max-of ((h . t:list[int])) :int (max-of h t))
+(def max-of ('()) :int 0)
+(def max-of (m:int '()) :int m)
+(def max-of (m:int (h . t:list[int])) :int
+	 (if (< m h) (max-of h t) (max-of m t)))
+
+(print (max-of (do '(3 9 2 7) :list[int])))
+
+;; variant patterns inside list patterns
+(defunion :token (:num value:int) (:op name:str))
+
+(def eval-rpn ('() (result:int)) :int result)
+(def eval-rpn (((:num n) . rest) stack) :int (eval-rpn rest (concat (list n) stack)))
+(def eval-rpn (((:op "+") . rest) (a b . stack)) :int (eval-rpn rest (concat (list (+ b a)) stack)))
+(def eval-rpn (((:op "*") . rest) (a b . stack)) :int (eval-rpn rest (concat (list (* b a)) stack)))
+(def eval-rpn (tokens stack) :int (error "incorrect expression"))
+
+(print (eval-rpn (list (num 2) (num 3) (op "+") (num 4) (op "*")) '()))
@@ -0,0 +1,25 @@
+(use std)
+
+;; macros from std library
+(when (> 2 1) (print "when: yes"))
+(unless (> 2 1) (print "unless: no"))
+
+(print (-> 5 (+ 1) (* 2) str))
+(print (->> '(1 2 3 4 5) (filter \(> _1 2)) (map \(* _1 10))))
+
+;; user-defined macro
+(defmacro my-if-not (c a b) `(if ,c ,b ,a))
+
+(print (my-if-not (> 1 2) "then" "else"))
+
+;; macros may have several clauses
+(defmacro sum () 0)
+(defmacro sum (x . rest) `(+ ,x (sum ,@rest)))
+
+(print (sum 1 2 3 4))
+
+;; variables ending with # are renamed so they do not clash with variables of the caller
+(defmacro swap-print (a b) `(let ((tmp# ,a)) (print ,b tmp#)))
+
+(set tmp "first")
+(swap-print tmp "second")
@@ -0,0 +1,28 @@
+(use std)
+
+(def my-map (f:func[a,b] l:list[a]) :list[b] (map f l))
+(def twice (m:func[func[a,b],list[a],list[b]] f:func[a,b] l:list[a]) :list[b] (m f (m f l)))
+
+(print (twice my-map (lambda (x:int) :int (* x 2)) '(1 2 3)))
+
+;; map from strings to lists of integers
+(deftype :index :map[str,list[int]])
+
+(def positions (words:list[str]) :index (positions' words 0 (do (map-of) :index)))
+(def positions' ('() _ idx:index) :index idx)
+(def positions' (w:list[str] n:int idx:index) :index
+  (set word (head w))
+  (set found (if (map-contains idx word) (map-get idx word) '()))
+  (positions' (tail w) (+ n 1) (do (map-put idx word (append found n)) :index)))
+
+(set idx (positions (words "to be or not to be")))
+(print (map-get idx "be") (map-get idx "not"))
+
+(def lengths (ls:list[list[int]]) :list[int] (map length ls))
+(print (lengths (map-values idx)))
+
+;; list of functions
+(def apply-all (fs:list[func[int,int]] x:int) :list[int]
+  (map (lambda (f:func[int,int]) :int (f x)) fs))
+(print (apply-all (list \(+ _1 1) (lambda (x:int) :int (* x 10))) 7))
+(print (type apply-all))
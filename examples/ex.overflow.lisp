@@ -0,0 +1,12 @@
+(def fact (n:int) :int
+	(if (<= n 1) 1 (* n (fact (- n 1)))))
+
+(print (fact 20))
+(print (fact 21))
+(print (fact 30))
+(print (/ (fact 30) (fact 28)))
+(print (+ 9223372036854775807 1))
+(print (- -9223372036854775808 1))
+(print (- (+ 9223372036854775807 10) 20))
+(print (= (+ 9223372036854775807 1) 9223372036854775808))
+(print (< 9223372036854775807 9223372036854775808))
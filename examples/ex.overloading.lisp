@@ -0,0 +1,30 @@
+(use std)
+
+; simple "type-safe" set of unique values
+(deftype :uset :list)
+
+(def uset-new () :uset '() :uset)
+(def uset-add (elem:any s:uset) :uset
+	 (if (contains elem s)
+	   s
+	   (do (append s elem) :uset)))
+
+(def contains (elem:any '()) :bool 'F)
+(def contains (elem:any l:list) :bool
+	 (if (= elem (head l)) 'T (contains elem (tail l))))
+
+; length from std library is extended for :uset
+(def length (s:uset) :int (+ 1000 (native.length s)))
+
+(set s (uset-add 2 (uset-add 1 (uset-add 1 (uset-new)))))
+(print s (length s))
+(print (length '(1 1 2)))
+
+; the most specific implementation is chosen regardless of declaration order
+(def describe (x:any) :str "any")
+(def describe (x:list) :str "list")
+(def describe (x:uset) :str "uset")
+(def describe (x:float) :str "float")
+(def describe (x:int) :str "int")
+
+(print (describe 1) (describe 1.5) (describe '(1)) (describe s) (describe 'T))
@@ -0,0 +1,22 @@
+(use rational)
+
+(print (/ 1 3))
+(print (/ 6 4))
+(print (/ 6 3))
+(print (+ (/ 1 3) (/ 1 6)))
+(print (- 1 (/ 1 3)))
+(print (* (/ 2 3) 3))
+(print (= (/ 4 2) 2))
+(print (set-contains (set-of (/ 4 2) (/ 1 2)) 2))
+(print (< (/ 1 3) (/ 1 2)))
+(print (>= 1 (/ 3 2)))
+(print (int (/ 7 2)))
+(print (float (/ 1 4)))
+(print (/ 1.0 4))
+(print (type (/ 1 3)))
+
+(def harmonic (n:int) :rational
+	(if (= n 0) 0 (+ (/ 1 n) (harmonic (- n 1)))))
+
+(print (harmonic 10))
+(print (try (/ 1 0) (catch e (error-message e))))
@@ -0,0 +1,35 @@
+(defrecord :point (x:int y:int))
+(defrecord :person (name:str age:int tags:list))
+(defrecord :circle (center:point radius:float))
+
+(set p (make-point 1 2))
+(print p)
+(print (type p))
+(print (point-x p) (point-y p))
+
+(set p2 (point-with-x p 10))
+(print p p2)
+
+(def dist2 (a:point b:point) :int
+	 (+ (* (- (point-x a) (point-x b)) (- (point-x a) (point-x b)))
+		(* (- (point-y a) (point-y b)) (- (point-y a) (point-y b)))))
+
+(print (dist2 p p2))
+
+(print (= p (make-point 1 2)) (= p p2))
+
+(set bob (make-person "Bob" 42 '("admin")))
+(print bob)
+(print (person-name bob) (+ (person-age bob) 1))
+(print (person-with-age bob 43))
+
+(set c (make-circle p 2))
+(print c)
+(print (point-y (circle-center c)) (circle-radius c))
+
+;; records can be used as keys of maps and in memoized functions
+(set m (map-of (make-point 0 0) "origin"))
+(print (map-get m (make-point 0 0)))
+
+(def' norm (p:point) :int (+ (point-x p) (point-y p)))
+(print (norm p2))
@@ -0,0 +1,33 @@
+; runtime errors handling
+
+(def parse-age (s:str) :int
+	 (set n (int s))
+	 (if (< n 0)
+	   (error "negative age" n)
+	   n))
+
+(def safe-age (s:str) :int
+	 (try
+	   (parse-age s)
+	   (catch err
+		  (print "cannot parse" s ":" (error-message err) (error-payload err))
+		  0)))
+
+(print (safe-age "42"))
+(print (safe-age "-3"))
+(print (safe-age "abc"))
+
+(print (try (/ 10 0) (catch e (error-message e))))
+
+(print (try (head '()) (catch e "empty")))
+
+; errors can be re-raised
+(print (try
+		 (try (error "inner") (catch e (error e)))
+		 (catch e (append "outer: " (error-message e)))))
+
+; error variable is visible only in the catch-block
+(def with-default (e:int) :int
+	 (set r (try (error "x") (catch e 5)))
+	 (+ r e))
+(print (with-default 10))
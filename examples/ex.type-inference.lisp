@@ -0,0 +1,25 @@
+(use std)
+
+; types of arguments and return values are inferred at call sites
+(def sq (x) (* x x))
+(def fib (n) (if (< n 2) n (+ (fib (- n 1)) (fib (- n 2)))))
+
+(def only-int (x:int) :int x)
+
+(print (only-int (sq 7)) (only-int (fib 10)))
+; (only-int (sq 1.5)) is type error: only-int: no matching function implementation found for [{:float <nil>}]
+
+; generic functions keep element types of lists, so no type casts are needed
+(def ascending? (l)
+	 (if (<= (length l) 1)
+	   'T
+	   (if (> (first l) (second l))
+		 'F
+		 (ascending? (tail l)))))
+
+(set nums '(1 2 3 5 8) :list[int])
+(print (ascending? nums) (ascending? (do '(3 2 1) :list[int])))
+
+; return types of lambdas with implicit arguments are inferred too
+(set inc \(+ _1 1))
+(print (only-int (inc 41)))
@@ -0,0 +1,41 @@
+(defunion :result (:ok value:int) (:err message:str))
+(defunion :shape (:circle radius:float) (:rect width:float height:float) :dot)
+
+(def safe-div (a:int 0) :result (err "division by zero"))
+(def safe-div (a:int b:int) :result (ok (/ a b)))
+
+(def describe ((:ok v)) :any (print "ok:" (+ v 0)))
+(def describe ((:err "division by zero")) :any (print "oops"))
+(def describe ((:err msg)) :any (print "error:" msg))
+
+(print (safe-div 10 2))
+(print (safe-div 1 0))
+(print (type (ok 1)))
+(describe (safe-div 10 2))
+(describe (safe-div 1 0))
+(describe (err "bad input"))
+
+(def area ((:circle r)) :float (* 3.0 r r))
+(def area ((:rect w h)) :float (* w h))
+(def area ((:dot)) :float 0.0)
+
+(print (area (circle 2)) (area (rect 2 3.5)) (area (dot)))
+
+;; variants are values: they can be compared and stored in lists
+(print (= (ok 1) (ok 1)) (= (ok 1) (ok 2)) (= (ok 1) (err "1")))
+
+(def total-area ('()) :float 0.0)
+(def total-area (shapes:list) :float
+	 (+ (area (do (head shapes) :shape)) (total-area (tail shapes))))
+
+(print (total-area (list (circle 1) (rect 1 2) (dot))))
+
+;; nested patterns
+(defunion :expr (:num n:int) (:add left:expr right:expr) (:mul left:expr right:expr))
+
+(def eval-expr ((:num n)) :int n)
+(def eval-expr ((:add (:num 0) r)) :int (eval-expr r))
+(def eval-expr ((:add l r)) :int (+ (eval-expr l) (eval-expr r)))
+(def eval-expr ((:mul l r)) :int (* (eval-expr l) (eval-expr r)))
+
+(print (eval-expr (add (num 0) (mul (num 6) (add (num 3) (num 4))))))
@@ -0,0 +1,36 @@
+(use std)
+
+(set v (vector-of 10 20 30))
+(print v)
+(print (type v))
+(print (vector-nth v 2))
+(print (vector-length v))
+(print (vector-push v 40))
+(print (vector-assoc v 1 15))
+(print v)
+(print (vector-slice (vector-of 1 2 3 4 5) 2 4))
+(print (vector->list v))
+(print (list->vector "abc"))
+
+;; vectors are lists
+(print (head v) (tail v))
+(print (nth 3 v) (length v))
+(print (append v 50))
+
+(def sum (l:list[int]) :int
+	 (if (empty l) 0 (+ (head l) (sum (tail l)))))
+
+(print (sum v))
+
+;; build vector of squares with accumulator
+(def squares (n:int acc:vector[int]) :vector[int]
+	 (if (= n 0)
+	   acc
+	   (squares (- n 1) (vector-push acc (* n n)))))
+
+(set big (squares 1000 (do (vector-of) :vector[int])))
+(print (vector-length big) (vector-nth big 1) (vector-nth big 1000))
+(print (sum big))
+(print (= (vector-of 1 2 3) (vector-slice (vector-of 0 1 2 3 4) 2 4)))
+
+(print (try (vector-nth v 4) (catch e (error-message e))))
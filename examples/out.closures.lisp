@@ -0,0 +1,4 @@
+6 11 :func[int,int]
+'(103 102 101)
+21
+'(0 3 6 9 12)
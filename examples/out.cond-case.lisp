@@ -0,0 +1,4 @@
+A B C F
+zero empty string empty list list of one element long list something else
+15
+12 12
@@ -0,0 +1,5 @@
+5
+abd
+1.5
+rect{w 2, h 3}
+3
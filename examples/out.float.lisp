@@ -0,0 +1,10 @@
+1.5 -0.25 3.0 1000.0
+3.75
+3.0
+3 3.5
+9.25
+2.5
+true false true false
+3 -3 2.0 2.75 0.5 42
+:float :int :float
+2.5
@@ -0,0 +1,5 @@
+negative zero positive
+equal less greater
+3
+too big fine failed
+111
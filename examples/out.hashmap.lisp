@@ -0,0 +1,19 @@
+{two 2, three 3, one 1}
+:map[str,int]
+2
+0
+true false
+3
+3 4
+4
+{four 4, two 2, three 3}
+true
+true
+6
+'(1)
+'('(a 1))
+3 2 1
+evaluating lookup a
+3
+3
+FMapGet: key not found: five
@@ -0,0 +1,16 @@
+:set[int]
+3
+true false
+3 4
+true
+true
+true
+true
+true
+true
+6
+#{red blue green}
+#{red green}
+8
+:set[str]
+2
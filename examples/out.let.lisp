@@ -0,0 +1,6 @@
+25
+11 1
+30
+25
+50005000
+22
@@ -0,0 +1,8 @@
+15
+empty one element two elements many elements
+true false
+true false
+'(a b c)
+h
+9
+20
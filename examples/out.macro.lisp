@@ -0,0 +1,6 @@
+when: yes
+12
+'(30 40 50)
+then
+10
+second first
@@ -0,0 +1,5 @@
+'(4 8 12)
+'(1 5) '(3)
+'(2 1 2 1)
+'(8 70)
+:func[list[func[int,int]],int,list[int]]
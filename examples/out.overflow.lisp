@@ -0,0 +1,9 @@
+2432902008176640000
+51090942171709440000
+265252859812191058636308480000000
+870
+9223372036854775808
+-9223372036854775809
+9223372036854775797
+true
+true
@@ -0,0 +1,3 @@
+'(1 2) 1002
+3
+int float list uset any
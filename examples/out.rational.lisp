@@ -0,0 +1,16 @@
+1/3
+3/2
+2
+1/2
+2/3
+2
+true
+true
+true
+false
+3
+0.25
+0.25
+:rational
+7381/2520
+FDiv: division by zero
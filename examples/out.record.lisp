@@ -0,0 +1,13 @@
+point{x 1, y 2}
+:point
+1 2
+point{x 1, y 2} point{x 10, y 2}
+81
+true false
+person{name Bob, age 42, tags '(admin)}
+Bob 43
+person{name Bob, age 43, tags '(admin)}
+circle{center point{x 1, y 2}, radius 2.0}
+2 2.0
+origin
+12
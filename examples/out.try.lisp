@@ -0,0 +1,9 @@
+42
+cannot parse -3 : negative age -3
+0
+cannot parse abc : FInt: cannot convert argument into Int: {Str: "abc"} '()
+0
+FDiv: division by zero
+empty
+outer: inner
+15
@@ -0,0 +1,3 @@
+49 55
+true false
+42
@@ -0,0 +1,10 @@
+(ok 5)
+(err division by zero)
+:result
+ok: 5
+oops
+error: bad input
+12.0 7.0 0.0
+true false false
+5.0
+42
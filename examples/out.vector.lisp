@@ -0,0 +1,18 @@
+[10 20 30]
+:vector[int]
+20
+3
+[10 20 30 40]
+[15 20 30]
+[10 20 30]
+[2 3 4]
+'(10 20 30)
+[a b c]
+10 [20 30]
+30 3
+[10 20 30 50]
+60
+1000 1000000 1
+333833500
+true
+Index is out of range: 4
@@ -1,4 +1,5 @@
 (contract :a)
+(contract :b)
 
 (def head (l:list[a]) :a (native.head l) :a)
  
@@ -1,3 +1,5 @@
+(contract :ord (< :ord :ord) :bool)
+
 (def < (a:int b:int) :bool (native.int.less a b))
 (def > (a:int b:int) :bool (native.int.less b a))
 (def <= (a:int b:int) :bool (not (native.int.less b a)))
@@ -7,3 +9,13 @@
 (def > (a:str b:str) :bool (native.str.less b a))
 (def <= (a:str b:str) :bool (not (native.str.less b a)))
 (def >= (a:str b:str) :bool (not (native.str.less a b)))
+
+(def < (a:rational b:rational) :bool (native.rational.less a b))
+(def > (a:rational b:rational) :bool (native.rational.less b a))
+(def <= (a:rational b:rational) :bool (not (native.rational.less b a)))
+(def >= (a:rational b:rational) :bool (not (native.rational.less a b)))
+
+(def < (a:float b:float) :bool (native.float.less a b))
+(def > (a:float b:float) :bool (native.float.less b a))
+(def <= (a:float b:float) :bool (not (native.float.less b a)))
+(def >= (a:float b:float) :bool (not (native.float.less a b)))
@@ -25,23 +25,23 @@
 
 
 ;; lazy map
-(def map (fn:func lst:list) :list
+(def map (fn:func[a,b] lst:list[a]) :list[b]
 	 (set
 	   iter
 	   (lambda
 		 (if (empty _1)
 		   '()
 		   (list (fn (head _1)) (tail _1)))))
-	 (gen iter lst))
+	 (gen iter lst) :list[b])
 
-(def map' (fn:func lst:list) :list
+(def map' (fn:func[a,b] lst:list[a]) :list[b]
 	 (set
 	   iter
 	   (lambda
 		 (if (empty _1)
 		   '()
 		   (list (fn (head _1)) (tail _1)))))
-	 (gen' iter lst))
+	 (gen' iter lst) :list[b])
 
 ;; take first n values from list
 (def take (n:int lst:list[a]) :list[a]
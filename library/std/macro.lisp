@@ -0,0 +1,16 @@
+;; (when condition body...) evaluates body if condition is true and returns empty list otherwise
+(defmacro when (c . body) `(if ,c (do ,@body) '()))
+
+;; (unless condition body...) evaluates body if condition is false and returns empty list otherwise
+(defmacro unless (c . body) `(if ,c '() (do ,@body)))
+
+;; threading macros:
+;; (-> x (f a) g) => (g (f x a))
+(defmacro -> (x) x)
+(defmacro -> (x (f . args) . rest) `(-> (,f ,x ,@args) ,@rest))
+(defmacro -> (x f . rest) `(-> (,f ,x) ,@rest))
+
+;; (->> x (f a) g) => (g (f a x))
+(defmacro ->> (x) x)
+(defmacro ->> (x (f . args) . rest) `(->> (,f ,@args ,x) ,@rest))
+(defmacro ->> (x f . rest) `(->> (,f ,x) ,@rest))
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"errors"
 	"flag"
 	"fmt"
 	"io"
@@ -8,6 +9,8 @@ import (
 	"log"
 	"os"
 	"path/filepath"
+
+	"github.com/avoronkov/spil/pkg/spil"
 )
 
 var (
@@ -15,6 +18,7 @@ var (
 	bigint bool
 	stat   bool
 	check  bool
+	repl   bool
 )
 
 func init() {
@@ -29,6 +33,9 @@ func init() {
 
 	flag.BoolVar(&check, "check", false, "make parsing and typechecking only")
 	flag.BoolVar(&check, "c", false, "make parsing and typechecking only (shorthand)")
+
+	flag.BoolVar(&repl, "repl", false, "run interactive REPL (default if no file is specified and stdin is a terminal)")
+	flag.BoolVar(&repl, "i", false, "run interactive REPL (shorthand)")
 }
 
 func doMain() int {
@@ -37,9 +44,17 @@ func doMain() int {
 		log.SetOutput(ioutil.Discard)
 	}
 
-	in := NewInterpreter(os.Stdout, getReleaseLibraryDir())
+	in := spil.NewInterpreter(os.Stdout, getReleaseLibraryDir())
 	in.UseBigInt(bigint)
 
+	if repl || (len(flag.Args()) == 0 && isTerminal(os.Stdin)) {
+		if err := spil.NewRepl(in, os.Stdin, os.Stdout).Run(); err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			return 1
+		}
+		return 0
+	}
+
 	var file string
 	var input io.Reader
 	if len(flag.Args()) >= 1 {
@@ -72,13 +87,24 @@ func doMain() int {
 		}
 		return 1
 	}
+	for _, w := range in.Warnings() {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", w)
+	}
 
 	if check {
 		return 0
 	}
 
-	if err := in.Run(); err != nil {
+	var args []string
+	if len(flag.Args()) > 1 {
+		args = flag.Args()[1:]
+	}
+	if err := in.Run(os.Stdin, args); err != nil {
 		fmt.Fprintf(os.Stderr, "%v\n", err)
+		var rerr *spil.RuntimeError
+		if errors.As(err, &rerr) {
+			rerr.PrintStack(os.Stderr)
+		}
 		return 1
 	}
 	if stat {
@@ -91,6 +117,14 @@ func main() {
 	os.Exit(doMain())
 }
 
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
 func getReleaseLibraryDir() string {
 	binPath, err := os.Executable()
 	if err != nil {
@@ -0,0 +1,163 @@
+package spil
+
+import (
+	"fmt"
+)
+
+const elseClause = Ident("else")
+
+// (cond (condition1 body...) (condition2 body...) ... (else body...))
+// returns result of the body of the first true condition.
+func (f *FuncRuntime) evalCond(se *Sexpr) (*Param, *Type, error) {
+	for _, c := range se.List[1:] {
+		clause, err := parseClause("cond", c)
+		if err != nil {
+			return nil, nil, err
+		}
+		if clause.List[0].V != elseClause {
+			res, err := f.evalParameter(&clause.List[0])
+			if err != nil {
+				return nil, nil, err
+			}
+			boolRes, ok := res.V.(Bool)
+			if !ok {
+				return nil, nil, fmt.Errorf("cond: condition %v should evaluate to boolean value, actual %v", clause.List[0], res)
+			}
+			if !bool(boolRes) {
+				continue
+			}
+		}
+		return f.lastBody(clause.List[1:])
+	}
+	return nil, nil, fmt.Errorf("cond: none of conditions is true")
+}
+
+// (case expr (pattern1 body...) (pattern2 body...) ... (else body...))
+// matches value of expr against patterns which have the same syntax as function arguments:
+// literals, variables, lists (h . t) and variants (:ok v).
+// Variables of the matched pattern are visible only in the body of the clause.
+func (f *FuncRuntime) evalCase(se *Sexpr) (*Param, *Type, error) {
+	if len(se.List) < 2 {
+		return nil, nil, fmt.Errorf("case: expected expression and clauses, found: %v", se)
+	}
+	value, err := f.evalParameter(&se.List[1])
+	if err != nil {
+		return nil, nil, err
+	}
+	params := []Param{*value}
+	for _, c := range se.List[2:] {
+		clause, err := parseClause("case", c)
+		if err != nil {
+			return nil, nil, err
+		}
+		if clause.List[0].V == elseClause {
+			return f.lastBody(clause.List[1:])
+		}
+		args, err := f.fi.interpret.casePattern(clause.List[0], TypeUnknown)
+		if err != nil {
+			return nil, nil, err
+		}
+		if !f.fi.matchArgs(args, params, map[string]Expr{}, map[string]Type{}) {
+			continue
+		}
+		f.saveArgVars(args)
+		f.bindArgs(args, params)
+		return f.lastBody(clause.List[1:])
+	}
+	return nil, nil, fmt.Errorf("case: no clause matches value %v", value)
+}
+
+func parseClause(form string, c Param) (*Sexpr, error) {
+	clause, ok := c.V.(*Sexpr)
+	if !ok || clause.Quoted || len(clause.List) < 2 {
+		return nil, withPos(c.Pos, fmt.Errorf("%v: expected clause (condition body...), found: %v", form, c))
+	}
+	return clause, nil
+}
+
+// casePattern parses pattern of the case clause.
+// Untyped variables get type t of the matched value.
+func (in *Interpret) casePattern(pattern Param, t Type) ([]Arg, error) {
+	args, err := parseArgs([]Param{pattern})
+	if err != nil {
+		return nil, withPos(pattern.Pos, err)
+	}
+	if len(args) != 1 {
+		return nil, withPos(pattern.Pos, fmt.Errorf("case: incorrect pattern %v", pattern))
+	}
+	arg := &args[0]
+	if arg.Pattern == nil && arg.V == nil && arg.T == TypeUnknown {
+		arg.T = t
+	}
+	if p := arg.Pattern; p != nil && p.Variant == "" && p.Tail != nil && p.Tail.T == TypeUnknown {
+		// element type of the list flows into elements of the pattern
+		if elem, err := in.elemType(t, TypeList); err == nil && elem != TypeUnknown && elem != TypeAny {
+			p.Tail.T = t
+		}
+	}
+	if err := in.resolvePatterns(args); err != nil {
+		return nil, withPos(pattern.Pos, err)
+	}
+	return args, nil
+}
+
+// Typers
+
+func (in *Interpret) condType(fname string, a *Sexpr, vars map[string]Type) (Type, error) {
+	rt := TypeNothing
+	for _, c := range a.List[1:] {
+		clause, err := parseClause("cond", c)
+		if err != nil {
+			return TypeUnknown, err
+		}
+		if clause.List[0].V != elseClause {
+			ct, err := in.exprType(fname, clause.List[0], vars)
+			if err != nil {
+				return TypeUnknown, err
+			}
+			if ct != TypeBool && ct != TypeUnknown {
+				return TypeUnknown, withPos(clause.List[0].Pos, fmt.Errorf("%v: condition in cond should return :bool, found: %v", fname, ct))
+			}
+		}
+		t, err := in.scopeType(fname, clause.List[1:], vars, nil)
+		if err != nil {
+			return TypeUnknown, err
+		}
+		rt = in.joinTypes(rt, t)
+	}
+	if rt == TypeNothing {
+		return TypeUnknown, fmt.Errorf("%v: cond expects at least one clause", fname)
+	}
+	return rt, nil
+}
+
+func (in *Interpret) caseType(fname string, a *Sexpr, vars map[string]Type) (Type, error) {
+	if len(a.List) < 3 {
+		return TypeUnknown, fmt.Errorf("%v: case expects expression and clauses, found: %v", fname, a)
+	}
+	vt, err := in.exprType(fname, a.List[1], vars)
+	if err != nil {
+		return TypeUnknown, err
+	}
+	rt := TypeNothing
+	for _, c := range a.List[2:] {
+		clause, err := parseClause("case", c)
+		if err != nil {
+			return TypeUnknown, err
+		}
+		bound := map[string]Type{}
+		if clause.List[0].V != elseClause {
+			args, err := in.casePattern(clause.List[0], vt)
+			if err != nil {
+				return TypeUnknown, prefixErr(fname, err)
+			}
+			addArgValues(bound, args)
+		}
+		t, err := in.scopeType(fname, clause.List[1:], vars, bound)
+		if err != nil {
+			return TypeUnknown, err
+		}
+		rt = in.joinTypes(rt, t)
+	}
+	return rt, nil
+}
@@ -0,0 +1,20 @@
+package spil
+
+import "testing"
+
+func TestCondCase(t *testing.T) {
+	tests := []programTest{
+		{"cond", "(def f (n:int) :str (cond ((< n 0) \"neg\") ((= n 0) \"zero\") (else \"pos\")))\n(print (f -1) (f 0) (f 1))", "neg zero pos\n", ""},
+		{"cond no else", "(print (cond (false 1)))", "", "cond: none of conditions is true"},
+		{"cond not bool", "(def f (n:int) :int (cond (n 1) (else 2)))", "", "condition in cond should return :bool, found: :int"},
+		{"cond types", "(def f (n:int) :int (cond ((= n 0) 1) (else \"a\")))", "", "expected :int actual :any"},
+		{"case literals", "(def f (x) :str (case x (1 \"one\") (\"a\" \"str\") ('() \"empty\") (else \"other\")))\n(print (f 1) (f \"a\") (f '()) (f 2))", "one str empty other\n", ""},
+		{"case list", "(print (case '(1 2 3) ((a b) \"two\") ((a . t) t)))", "'(2 3)\n", ""},
+		{"case scope", "(set h 0)\n(print (case '(1 2) ((h . t) h)) h)", "1 0\n", ""},
+		{"case types", "(def f (l:list[int]) :int (case l ((h . t) h) (else 0)))\n(print (f (do '(5) :list[int])))", "5\n", ""},
+		{"case variant", "(defunion :opt (:some v:int) :none)\n(def f (o:opt) :int (case o ((:some v) v) (else 0)))\n(print (f (some 3)) (f (none)))", "3 0\n", ""},
+		{"case no match", "(print (case 3 (1 \"one\")))", "", "case: no clause matches value"},
+		{"case tail call", "(def f (n:int) :int (case n (0 0) (m (f (- m 1)))))\n(print (f 100000))", "0\n", ""},
+	}
+	checkPrograms(t, tests)
+}
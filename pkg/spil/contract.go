@@ -0,0 +1,138 @@
+package spil
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// contractFunc is a function required by contract: (name :arg1 :arg2 ...) :return
+type contractFunc struct {
+	name string
+	args []Type
+	ret  Type
+}
+
+func (c contractFunc) String() string {
+	b := &strings.Builder{}
+	b.WriteString("(" + c.name)
+	for _, a := range c.args {
+		b.WriteString(" " + a.String())
+	}
+	b.WriteString(")")
+	if c.ret != TypeUnknown {
+		b.WriteString(" " + c.ret.String())
+	}
+	return b.String()
+}
+
+// expand substitutes contracts in the signature with the bound types.
+func (c contractFunc) expand(binds map[string]Type) contractFunc {
+	res := contractFunc{name: c.name, ret: c.ret.Expand(binds)}
+	for _, a := range c.args {
+		res.args = append(res.args, a.Expand(binds))
+	}
+	return res
+}
+
+// parseContractFuncs parses list of signatures (fn1 :type1 :type2) :return (fn2 ...) :return ...
+// Return types are optional.
+func (in *Interpret) parseContractFuncs(args []Param) ([]contractFunc, error) {
+	var funcs []contractFunc
+	for i := 0; i < len(args); i++ {
+		se, ok := args[i].V.(*Sexpr)
+		if !ok || se.Quoted || se.Empty() {
+			return nil, fmt.Errorf("expected function signature (name :type1 :type2 ...), found %v", args[i])
+		}
+		name, ok := se.List[0].V.(Ident)
+		if !ok {
+			return nil, fmt.Errorf("expected function name, found %v", se.List[0])
+		}
+		fn := contractFunc{name: string(name), ret: TypeUnknown}
+		for _, a := range se.List[1:] {
+			id, _ := a.V.(Ident)
+			t, err := in.parseType(string(id))
+			if err != nil {
+				return nil, fmt.Errorf("%v: %w", string(name), err)
+			}
+			fn.args = append(fn.args, t)
+		}
+		if i+1 < len(args) {
+			if id, ok := args[i+1].V.(Ident); ok {
+				t, err := in.parseType(string(id))
+				if err != nil {
+					return nil, fmt.Errorf("%v: %w", string(name), err)
+				}
+				fn.ret = t
+				i++
+			}
+		}
+		funcs = append(funcs, fn)
+	}
+	return funcs, nil
+}
+
+// checkContracts verifies that types bound to contracts provide functions required by these contracts.
+func (in *Interpret) checkContracts(binds map[string]Type) error {
+	names := make([]string, 0, len(binds))
+	for name := range binds {
+		if len(in.contractFuncs[Type(name)]) > 0 {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		t := binds[name]
+		if t == TypeUnknown || t == TypeAny || in.IsGeneric(t) {
+			continue
+		}
+		for _, fn := range in.contractFuncs[Type(name)] {
+			if err := in.checkContractFunc(fn.expand(binds)); err != nil {
+				return &contractError{t: t, contract: Type(name), err: err}
+			}
+		}
+	}
+	return nil
+}
+
+func (in *Interpret) checkContractFunc(fn contractFunc) error {
+	params := make([]Param, 0, len(fn.args))
+	for _, a := range fn.args {
+		if a == TypeUnknown || in.IsGeneric(a) {
+			// not all types are known yet
+			return nil
+		}
+		params = append(params, Param{T: a})
+	}
+	f, ok := in.funcs[fn.name]
+	if !ok {
+		return fmt.Errorf("function %v is not defined", fn)
+	}
+	_, rt, _, err := f.TryBind(params)
+	if err != nil {
+		return fmt.Errorf("function %v is not defined", fn)
+	}
+	if fn.ret != TypeUnknown && !in.IsGeneric(fn.ret) && rt != TypeUnknown {
+		if ok, _ := in.canConvertType(rt, fn.ret); !ok {
+			return fmt.Errorf("function %v returns %v", fn, rt)
+		}
+	}
+	return nil
+}
+
+// contractError reports that type bound to contract does not provide required function.
+type contractError struct {
+	t        Type
+	contract Type
+	err      error
+}
+
+func (e *contractError) Error() string {
+	return fmt.Sprintf("type %v does not satisfy contract %v: %v", e.t.String(), e.contract.String(), e.err)
+}
+
+func isContractError(err error) bool {
+	var ce *contractError
+	return errors.As(err, &ce)
+}
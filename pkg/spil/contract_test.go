@@ -0,0 +1,23 @@
+package spil
+
+import "testing"
+
+func TestContractFuncs(t *testing.T) {
+	maxDef := "(def max2 (a:ord b:ord) :ord (if (< a b) b a))\n"
+	tests := []programTest{
+		{"int", maxDef + "(print (max2 1 2))", "2\n", ""},
+		{"str", maxDef + `(print (max2 "b" "a"))`, "b\n", ""},
+		{"record", maxDef + "(defrecord :p (x:int))\n(print (max2 (make-p 1) (make-p 2)))",
+			"", "max2: type :p does not satisfy contract :ord: function (< :p :p) :bool is not defined"},
+		{"generic caller", maxDef + "(def max3 (a:ord b:ord c:ord) :ord (max2 a (max2 b c)))\n(print (max3 1 3 2))", "3\n", ""},
+		{"user contract", "(contract :sized (size :sized) :int)\n(def size (s:str) :int 1)\n(def g (x:sized) :int (size x))\n(print (g \"a\"))", "1\n", ""},
+		{"missing function", "(contract :sized (size :sized) :int)\n(def size (s:str) :int 1)\n(def g (x:sized) :int (size x))\n(print (g 1))",
+			"", "g: type :int does not satisfy contract :sized: function (size :int) :int is not defined"},
+		{"return type", "(contract :sized (size :sized) :int)\n(def size (s:str) :str s)\n(def g (x:sized) :any (size x))\n(print (g \"a\"))",
+			"", "g: type :str does not satisfy contract :sized: function (size :str) :int returns :str"},
+		{"several types", "(contract (:k :v) (pair :k :v) :str)\n(def pair (k:int v:str) :str v)\n(def g (k:k v:v) :str (pair k v))\n(print (g 1 2))",
+			"", "g: type :int does not satisfy contract :k: function (pair :int :int) :str is not defined"},
+		{"bad signature", "(contract :sized size)", "", "expected function signature"},
+	}
+	checkPrograms(t, tests)
+}
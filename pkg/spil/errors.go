@@ -0,0 +1,137 @@
+package spil
+
+import (
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Pos is a position of expression in the source file.
+type Pos struct {
+	File string
+	Line int
+	Col  int
+}
+
+func (p Pos) String() string {
+	return fmt.Sprintf("%v:%d:%d", p.File, p.Line, p.Col)
+}
+
+// PosError is an error which occurred at the specific position of the source file.
+type PosError struct {
+	Pos Pos
+	Err error
+}
+
+func (e *PosError) Error() string {
+	return fmt.Sprintf("%v: %v", e.Pos, e.Err)
+}
+
+func (e *PosError) Unwrap() error {
+	return e.Err
+}
+
+// withPos attaches position to error unless it already has one.
+func withPos(pos *Pos, err error) error {
+	if err == nil || pos == nil {
+		return err
+	}
+	var re *RuntimeError
+	if errors.As(err, &re) {
+		// remember the place of the call in the caller function
+		if re.pos == nil {
+			re.pos = pos
+		}
+		return err
+	}
+	if _, ok := err.(*PosError); ok {
+		return err
+	}
+	return &PosError{Pos: *pos, Err: err}
+}
+
+// prefixErr adds textual prefix to error message keeping position (if any) at the beginning.
+func prefixErr(prefix interface{}, err error) error {
+	if pe, ok := err.(*PosError); ok {
+		return &PosError{Pos: pe.Pos, Err: fmt.Errorf("%v: %w", prefix, pe.Err)}
+	}
+	return fmt.Errorf("%v: %w", prefix, err)
+}
+
+// Frame is a function call in the spil call stack.
+type Frame struct {
+	// function name
+	Func string
+	// matched function implementation (argument pattern)
+	Impl string
+	// place in the function where the failure happened
+	Pos *Pos
+	// number of tail calls of the function which were elided
+	TailCalls int
+}
+
+func (f Frame) String() string {
+	res := f.Func
+	if f.Impl != "" {
+		res += " " + f.Impl
+	}
+	if f.Pos != nil {
+		res += fmt.Sprintf(" at %v", *f.Pos)
+	}
+	if f.TailCalls > 0 {
+		res += fmt.Sprintf(" [%d tail calls elided]", f.TailCalls)
+	}
+	return res
+}
+
+// RuntimeError is an error which occurred during evaluation of spil program.
+// It contains the spil call stack: the innermost function call goes first.
+type RuntimeError struct {
+	Err   error
+	Stack []Frame
+
+	// position of the failed call in the current (not yet recorded) function
+	pos *Pos
+}
+
+func (e *RuntimeError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *RuntimeError) Unwrap() error {
+	return e.Err
+}
+
+func (e *RuntimeError) PrintStack(w io.Writer) {
+	fmt.Fprintf(w, "Stack trace:\n")
+	for i := 0; i < len(e.Stack); {
+		// Collapse repeating frames of recursive calls
+		j := i + 1
+		for j < len(e.Stack) && e.Stack[j] == e.Stack[i] {
+			j++
+		}
+		fmt.Fprintf(w, "  %v\n", e.Stack[i])
+		if j-i > 1 {
+			fmt.Fprintf(w, "  ... repeated %d more times\n", j-i-1)
+		}
+		i = j
+	}
+}
+
+// addFrame records function call into the call stack of runtime error.
+func addFrame(err error, frame Frame) error {
+	var re *RuntimeError
+	if !errors.As(err, &re) {
+		re = &RuntimeError{Err: err}
+		if pe, ok := err.(*PosError); ok {
+			re.pos = &pe.Pos
+		}
+		err = re
+	}
+	if frame.Pos == nil {
+		frame.Pos = re.pos
+	}
+	re.pos = nil
+	re.Stack = append(re.Stack, frame)
+	return err
+}
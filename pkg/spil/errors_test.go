@@ -0,0 +1,43 @@
+package spil
+
+import (
+	"errors"
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+func TestRuntimeErrorStack(t *testing.T) {
+	src := `(def count-down (0 acc) (native.head acc))
+(def count-down (n acc) (count-down (- n 1) acc))
+
+(def deep (0) (count-down 5 '()))
+(def deep (n) (+ 1 (deep (- n 1))))
+
+(print (deep 2))
+`
+	in := NewInterpreter(ioutil.Discard, getTestLibraryDir())
+	if err := in.Parse("test.lisp", strings.NewReader(src)); err != nil {
+		t.Fatalf("Parse() failed: %v", err)
+	}
+	err := in.Run(nil, nil)
+	if err == nil {
+		t.Fatalf("Run() should fail")
+	}
+	var rerr *RuntimeError
+	if !errors.As(err, &rerr) {
+		t.Fatalf("Run() should return RuntimeError, found: %T (%v)", err, err)
+	}
+	out := &strings.Builder{}
+	rerr.PrintStack(out)
+	exp := `Stack trace:
+  count-down (0 acc) at test.lisp:1:25 [5 tail calls elided]
+  deep (0) at test.lisp:4:15
+  deep (n) at test.lisp:5:20
+  ... repeated 1 more times
+  __main__ at test.lisp:7:8
+`
+	if act := out.String(); act != exp {
+		t.Errorf("Incorrect stack trace:\nexpected %q,\n  actual %q", exp, act)
+	}
+}
@@ -1,4 +1,4 @@
-package main
+package spil
 
 import (
 	"errors"
@@ -233,9 +233,12 @@ func (s *Sexpr) Empty() bool {
 }
 
 func (s *Sexpr) Append(params []Param) (*Param, error) {
+	// copy elements, so that lists never share backing arrays
+	list := make([]Param, 0, len(s.List)+len(params))
+	list = append(list, s.List...)
 	return &Param{
 		V: &Sexpr{
-			List:   append(s.List, params...),
+			List:   append(list, params...),
 			Quoted: s.Quoted,
 		},
 		T: TypeList,
@@ -246,12 +249,83 @@ func (s *Sexpr) Type() Type {
 	return TypeList
 }
 
+// ErrorValue is a runtime error represented as a value.
+// It is raised with (error ...) and caught with (try ... (catch ...)).
+type ErrorValue struct {
+	Message string
+	Payload Param
+}
+
+var _ Expr = (*ErrorValue)(nil)
+var _ error = (*ErrorValue)(nil)
+
+func NewErrorValue(err error) *ErrorValue {
+	var ev *ErrorValue
+	if errors.As(err, &ev) {
+		return ev
+	}
+	// strip stack and position information from message
+	for {
+		if re, ok := err.(*RuntimeError); ok {
+			err = re.Err
+		} else if pe, ok := err.(*PosError); ok {
+			err = pe.Err
+		} else {
+			break
+		}
+	}
+	return &ErrorValue{
+		Message: err.Error(),
+		Payload: Param{V: QEmpty, T: TypeList},
+	}
+}
+
+func (e *ErrorValue) Error() string {
+	if l, ok := e.Payload.V.(List); ok && l.Empty() {
+		return e.Message
+	}
+	b := &strings.Builder{}
+	b.WriteString(e.Message + ": ")
+	e.Payload.V.Print(b)
+	return b.String()
+}
+
+func (e *ErrorValue) String() string {
+	return fmt.Sprintf("{Error: %q %v}", e.Message, e.Payload.V)
+}
+
+func (e *ErrorValue) Hash() (string, error) {
+	h, err := e.Payload.V.Hash()
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("{Error: %q %v}", e.Message, h), nil
+}
+
+func (e *ErrorValue) Print(w io.Writer) {
+	io.WriteString(w, "error: "+e.Error())
+}
+
+func (e *ErrorValue) Type() Type {
+	return TypeError
+}
+
 func Equal(a, b Expr) bool {
 	al, alist := a.(List)
 	if alist && al.Empty() {
 		bl, blist := b.(List)
 		return blist && bl.Empty()
 	}
+	if _, ok := a.(*Rational); ok {
+		return equalRat(a, b)
+	}
+	if _, ok := b.(*Rational); ok {
+		return equalRat(a, b)
+	}
+	if ia, ok := a.(Int); ok {
+		ib, ok := b.(Int)
+		return ok && ia.Eq(ib)
+	}
 	if a.Type() != b.Type() {
 		return false
 	}
@@ -0,0 +1,115 @@
+package spil
+
+import (
+	"fmt"
+	"io"
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+type Float float64
+
+var _ Expr = Float(0)
+
+var reFloat = regexp.MustCompile(`^[-+]?([0-9]+\.[0-9]*|\.[0-9]+|[0-9]+)([eE][-+]?[0-9]+)?$`)
+
+// ParseFloat parses float literals like "1.5", "-0.25", "1e10".
+// Tokens without decimal point or exponent are not considered as floats.
+func ParseFloat(token string) (Float, bool) {
+	if !reFloat.MatchString(token) || !strings.ContainsAny(token, ".eE") {
+		return 0, false
+	}
+	f, err := strconv.ParseFloat(token, 64)
+	if err != nil {
+		return 0, false
+	}
+	return Float(f), true
+}
+
+func (f Float) String() string {
+	return fmt.Sprintf("{Float: %v}", f.format())
+}
+
+func (f Float) Hash() (string, error) {
+	return f.String(), nil
+}
+
+func (f Float) Print(w io.Writer) {
+	io.WriteString(w, f.format())
+}
+
+// format float so that it is distinguishable from integer.
+func (f Float) format() string {
+	s := strconv.FormatFloat(float64(f), 'g', -1, 64)
+	if !strings.ContainsAny(s, ".eIN") {
+		s += ".0"
+	}
+	return s
+}
+
+func (f Float) Type() Type {
+	return TypeFloat
+}
+
+// toFloat converts numeric value into float64.
+func toFloat(e Expr) (float64, bool) {
+	switch a := e.(type) {
+	case Float:
+		return float64(a), true
+	case *Rational:
+		f, _ := a.value.Float64()
+		return f, true
+	case *BigInt:
+		f, _ := new(big.Float).SetInt(a.value).Float64()
+		return f, true
+	case Int:
+		return float64(a.Int64()), true
+	}
+	return 0, false
+}
+
+func hasFloats(args []Param) bool {
+	for _, arg := range args {
+		if _, ok := arg.V.(Float); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// floatOp folds arguments (integers are promoted to floats) with the specified operation.
+func floatOp(name string, args []Param, op func(a, b float64) float64) (*Param, error) {
+	var result float64
+	for i, arg := range args {
+		a, ok := toFloat(arg.V)
+		if !ok {
+			return nil, fmt.Errorf("%v: expected numeric argument in position %v, found %v", name, i, arg)
+		}
+		if i == 0 {
+			result = a
+		} else {
+			result = op(result, a)
+		}
+	}
+	return &Param{V: Float(result), T: TypeFloat}, nil
+}
+
+// promote converts integer (or rational) value into float (or rational) if such type is expected.
+func (in *Interpret) promote(p *Param, t Type) *Param {
+	switch in.UnaliasType(t) {
+	case TypeFloat:
+		switch p.V.(type) {
+		case Int, *Rational:
+			f, _ := toFloat(p.V)
+			return &Param{V: Float(f), T: TypeFloat, Pos: p.Pos}
+		}
+	case TypeRational:
+		if _, ok := p.V.(Int); ok {
+			r, _ := toRat(p.V)
+			return &Param{V: &Rational{value: r, big: isBigMode(p.V)}, T: TypeRational, Pos: p.Pos}
+		}
+	}
+	return p
+}
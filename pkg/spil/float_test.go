@@ -0,0 +1,65 @@
+package spil
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestParseFloat(t *testing.T) {
+	tests := []struct {
+		token string
+		ok    bool
+		exp   Float
+	}{
+		{"1.5", true, 1.5},
+		{"-0.25", true, -0.25},
+		{".5", true, 0.5},
+		{"3.", true, 3},
+		{"1e3", true, 1000},
+		{"2.5E-1", true, 0.25},
+		{"12", false, 0},
+		{"inf", false, 0},
+		{"NaN", false, 0},
+		{"e10", false, 0},
+		{"1.2.3", false, 0},
+	}
+	for _, test := range tests {
+		t.Run(test.token, func(t *testing.T) {
+			act, ok := ParseFloat(test.token)
+			if ok != test.ok || act != test.exp {
+				t.Errorf("ParseFloat(%q) failed: expected (%v, %v), actual (%v, %v)", test.token, test.exp, test.ok, act, ok)
+			}
+		})
+	}
+}
+
+func TestFloatPrint(t *testing.T) {
+	tests := []struct {
+		f   Float
+		exp string
+	}{
+		{1.5, "1.5"},
+		{3, "3.0"},
+		{-2, "-2.0"},
+		{1e21, "1e+21"},
+	}
+	for _, test := range tests {
+		t.Run(test.exp, func(t *testing.T) {
+			b := &strings.Builder{}
+			test.f.Print(b)
+			if act := b.String(); act != test.exp {
+				t.Errorf("Incorrect float representation: expected %q, actual %q", test.exp, act)
+			}
+		})
+	}
+}
+
+func TestFIntNotFinite(t *testing.T) {
+	in := NewInterpreter(&strings.Builder{}, getTestLibraryDir())
+	for _, f := range []Float{Float(math.NaN()), Float(math.Inf(1)), Float(math.Inf(-1))} {
+		if _, err := in.FInt([]Param{{V: f, T: TypeFloat}}); err == nil {
+			t.Errorf("FInt(%v) should fail", f)
+		}
+	}
+}
@@ -1,8 +1,11 @@
-package main
+package spil
 
 import (
 	"fmt"
+	"math/big"
 	"os"
+	"strconv"
+	"strings"
 	"unicode"
 )
 
@@ -17,6 +20,8 @@ type nativeFunc struct {
 	fn     func([]Param) (*Param, error)
 	ret    Type
 	binder func([]Param) error
+	// typer checks arguments and returns result type (used instead of binder and ret if specified)
+	typer func([]Param) (Type, error)
 }
 
 func (n *nativeFunc) Eval(args []Param) (*Param, error) {
@@ -28,6 +33,13 @@ func (n *nativeFunc) ReturnType() Type {
 }
 
 func (n *nativeFunc) TryBind(params []Param) (int, Type, map[string]Type, error) {
+	if n.typer != nil {
+		t, err := n.typer(params)
+		if err != nil {
+			return -1, TypeUnknown, nil, fmt.Errorf("%v: %v", n.name, err)
+		}
+		return 0, t, nil, nil
+	}
 	if err := n.binder(params); err != nil {
 		return -1, TypeUnknown, nil, fmt.Errorf("%v: %v", n.name, err)
 	}
@@ -43,7 +55,24 @@ func EvalerFunc(name string, fn func([]Param) (*Param, error), binder func([]Par
 	}
 }
 
+// TypedEvalerFunc creates native function which result type depends on types of arguments.
+// ret is the most general result type.
+func TypedEvalerFunc(name string, fn func([]Param) (*Param, error), typer func([]Param) (Type, error), ret Type) Evaler {
+	return &nativeFunc{
+		name:  name,
+		fn:    fn,
+		ret:   ret,
+		typer: typer,
+	}
+}
+
 func FPlus(args []Param) (*Param, error) {
+	if hasFloats(args) {
+		return floatOp("FPlus", args, func(a, b float64) float64 { return a + b })
+	}
+	if hasRationals(args) {
+		return ratOp("FPlus", args, func(a, b *big.Rat) (*big.Rat, error) { return a.Add(a, b), nil })
+	}
 	var result Int
 	for i, arg := range args {
 		a, ok := arg.V.(Int)
@@ -60,6 +89,12 @@ func FPlus(args []Param) (*Param, error) {
 }
 
 func FMinus(args []Param) (*Param, error) {
+	if hasFloats(args) {
+		return floatOp("FMinus", args, func(a, b float64) float64 { return a - b })
+	}
+	if hasRationals(args) {
+		return ratOp("FMinus", args, func(a, b *big.Rat) (*big.Rat, error) { return a.Sub(a, b), nil })
+	}
 	var result Int
 	for i, arg := range args {
 		a, ok := arg.V.(Int)
@@ -76,6 +111,12 @@ func FMinus(args []Param) (*Param, error) {
 }
 
 func FMultiply(args []Param) (*Param, error) {
+	if hasFloats(args) {
+		return floatOp("FMultiply", args, func(a, b float64) float64 { return a * b })
+	}
+	if hasRationals(args) {
+		return ratOp("FMultiply", args, func(a, b *big.Rat) (*big.Rat, error) { return a.Mul(a, b), nil })
+	}
 	var result Int
 	for i, arg := range args {
 		a, ok := arg.V.(Int)
@@ -92,6 +133,9 @@ func FMultiply(args []Param) (*Param, error) {
 }
 
 func FDiv(args []Param) (*Param, error) {
+	if hasFloats(args) {
+		return floatOp("FDiv", args, func(a, b float64) float64 { return a / b })
+	}
 	var result Int
 	for i, arg := range args {
 		a, ok := arg.V.(Int)
@@ -101,6 +145,9 @@ func FDiv(args []Param) (*Param, error) {
 		if i == 0 {
 			result = a
 		} else {
+			if isZero(a) {
+				return nil, fmt.Errorf("FDiv: division by zero")
+			}
 			result = result.Div(a)
 		}
 	}
@@ -119,6 +166,9 @@ func FMod(args []Param) (*Param, error) {
 	if !ok {
 		return nil, fmt.Errorf("FMod: second argument should be integer, found %v", args[1])
 	}
+	if isZero(b) {
+		return nil, fmt.Errorf("FMod: division by zero")
+	}
 	return &Param{V: a.Mod(b), T: TypeInt}, nil
 }
 
@@ -137,6 +187,21 @@ func FIntLess(args []Param) (*Param, error) {
 	return &Param{V: Bool(a.Less(b)), T: TypeBool}, nil
 }
 
+func FFloatLess(args []Param) (*Param, error) {
+	if len(args) != 2 {
+		return nil, fmt.Errorf("FFloatLess: expected 2 arguments, found %v", args)
+	}
+	a, ok := toFloat(args[0].V)
+	if !ok {
+		return nil, fmt.Errorf("FFloatLess: first argument should be number, found %v", args[0])
+	}
+	b, ok := toFloat(args[1].V)
+	if !ok {
+		return nil, fmt.Errorf("FFloatLess: second argument should be number, found %v", args[1])
+	}
+	return &Param{V: Bool(a < b), T: TypeBool}, nil
+}
+
 func FStrLess(args []Param) (*Param, error) {
 	if len(args) != 2 {
 		return nil, fmt.Errorf("FStrLess: expected 2 arguments, found %v", args)
@@ -211,9 +276,9 @@ type Appender interface {
 	Append([]Param) (*Param, error)
 }
 
-func FAppend(args []Param) (*Param, error) {
+func (in *Interpret) FAppend(args []Param) (*Param, error) {
 	if len(args) == 0 {
-		return &Param{V: QEmpty, T: TypeList}, nil
+		return &Param{V: QEmpty, T: in.listOf(TypeNothing)}, nil
 	}
 	if len(args) == 1 {
 		return &args[0], nil
@@ -222,16 +287,25 @@ func FAppend(args []Param) (*Param, error) {
 	if !ok {
 		return nil, fmt.Errorf("FAppend(1): expected first argument to be Appender, found %v", args[0])
 	}
-	return a.Append(args[1:])
+	res, err := a.Append(args[1:])
+	if err != nil {
+		return nil, err
+	}
+	switch a.(type) {
+	case *Sexpr, *Vector:
+		res.T, _ = in.AppendArgs(args)
+	}
+	return res, nil
 }
 
-func FList(args []Param) (*Param, error) {
+func (in *Interpret) FList(args []Param) (*Param, error) {
 	s := new(Sexpr)
 	for _, a := range args {
 		s.List = append(s.List, a)
 	}
 	s.Quoted = true
-	return &Param{V: s, T: TypeList}, nil
+	t, _ := in.ListArgs(args)
+	return &Param{V: s, T: t}, nil
 }
 
 // test if symbol is white-space
@@ -264,6 +338,42 @@ func FEol(args []Param) (*Param, error) {
 	return &Param{V: Bool(s == "\n"), T: TypeBool}, nil
 }
 
+// convert string or number into float
+func FFloat(args []Param) (*Param, error) {
+	if len(args) != 1 {
+		return nil, fmt.Errorf("FFloat: expected exaclty one argument, found %v", args)
+	}
+	if s, ok := args[0].V.(Str); ok {
+		str := strings.TrimSpace(string(s))
+		if f, ok := ParseFloat(str); ok {
+			return &Param{V: f, T: TypeFloat}, nil
+		}
+		f, err := strconv.ParseFloat(str, 64)
+		if err != nil {
+			return nil, fmt.Errorf("FFloat: cannot convert argument into Float: %v", s)
+		}
+		return &Param{V: Float(f), T: TypeFloat}, nil
+	}
+	f, ok := toFloat(args[0].V)
+	if !ok {
+		return nil, fmt.Errorf("FFloat: expected argument to be Str or number, found %v", args[0])
+	}
+	return &Param{V: Float(f), T: TypeFloat}, nil
+}
+
+// convert value into its string representation
+func FStr(args []Param) (*Param, error) {
+	if len(args) != 1 {
+		return nil, fmt.Errorf("FStr: expected exaclty one argument, found %v", args)
+	}
+	if s, ok := args[0].V.(Str); ok {
+		return &Param{V: s, T: TypeStr}, nil
+	}
+	b := &strings.Builder{}
+	args[0].V.Print(b)
+	return &Param{V: Str(b.String()), T: TypeStr}, nil
+}
+
 func FOpen(args []Param) (*Param, error) {
 	if len(args) != 1 {
 		return nil, fmt.Errorf("FOpen: expected exaclty one argument, found %v", args)
@@ -279,6 +389,52 @@ func FOpen(args []Param) (*Param, error) {
 	return &Param{V: NewLazyInput(file), T: TypeStr}, nil
 }
 
+// (error "message" payload)
+// (error err)
+func FError(args []Param) (*Param, error) {
+	if len(args) == 0 || len(args) > 2 {
+		return nil, fmt.Errorf("FError: expected one or two arguments, found %v", args)
+	}
+	if ev, ok := args[0].V.(*ErrorValue); ok && len(args) == 1 {
+		// re-raise
+		return nil, ev
+	}
+	msg, ok := args[0].V.(Str)
+	if !ok {
+		return nil, fmt.Errorf("FError: expected first argument to be Str, found %v", args[0])
+	}
+	ev := &ErrorValue{
+		Message: string(msg),
+		Payload: Param{V: QEmpty, T: TypeList},
+	}
+	if len(args) == 2 {
+		ev.Payload = args[1]
+	}
+	return nil, ev
+}
+
+func FErrorMessage(args []Param) (*Param, error) {
+	if len(args) != 1 {
+		return nil, fmt.Errorf("FErrorMessage: expected exaclty one argument, found %v", args)
+	}
+	ev, ok := args[0].V.(*ErrorValue)
+	if !ok {
+		return nil, fmt.Errorf("FErrorMessage: expected argument to be Error, found %v", args[0])
+	}
+	return &Param{V: Str(ev.Message), T: TypeStr}, nil
+}
+
+func FErrorPayload(args []Param) (*Param, error) {
+	if len(args) != 1 {
+		return nil, fmt.Errorf("FErrorPayload: expected exaclty one argument, found %v", args)
+	}
+	ev, ok := args[0].V.(*ErrorValue)
+	if !ok {
+		return nil, fmt.Errorf("FErrorPayload: expected argument to be Error, found %v", args[0])
+	}
+	return &ev.Payload, nil
+}
+
 func FType(args []Param) (*Param, error) {
 	if len(args) != 1 {
 		return nil, fmt.Errorf(": expected exaclty one argument, found %v", args)
@@ -295,6 +451,13 @@ type Nther interface {
 }
 
 // Binders
+func NoArgs(params []Param) error {
+	if len(params) != 0 {
+		return fmt.Errorf("expected no arguments, found %v", params)
+	}
+	return nil
+}
+
 func SingleArg(params []Param) error {
 	if len(params) != 1 {
 		return fmt.Errorf("expected exaclty one argument, found %v", params)
@@ -318,6 +481,60 @@ func (in *Interpret) AllInts(params []Param) error {
 	return nil
 }
 
+// Numbers checks that all arguments are numeric.
+// Result is :float if any of arguments is :float, :rational if any of arguments is :rational and :int otherwise.
+func (in *Interpret) Numbers(params []Param) (Type, error) {
+	result := TypeInt
+	for i, p := range params {
+		if p.T == TypeUnknown || in.IsContract(p.T) {
+			continue
+		}
+		if ok, err := in.canConvertType(p.T, TypeInt); err == nil && ok {
+			continue
+		}
+		if ok, err := in.canConvertType(p.T, TypeRational); err == nil && ok {
+			if result == TypeInt {
+				result = TypeRational
+			}
+			continue
+		}
+		ok, err := in.canConvertType(p.T, TypeFloat)
+		if err != nil {
+			return TypeUnknown, err
+		}
+		if !ok {
+			return TypeUnknown, fmt.Errorf("Expected all numeric arguments, found %v at position %v", p, i)
+		}
+		result = TypeFloat
+	}
+	return result, nil
+}
+
+func (in *Interpret) TwoNumbers(params []Param) error {
+	if len(params) != 2 {
+		return fmt.Errorf("expected 2 arguments, found %v", params)
+	}
+	_, err := in.Numbers(params)
+	return err
+}
+
+// NumberArg checks that function is called with one numeric argument
+func (in *Interpret) NumberArg(params []Param) error {
+	if len(params) != 1 {
+		return fmt.Errorf("expected exaclty one argument, found %v", params)
+	}
+	_, err := in.Numbers(params)
+	return err
+}
+
+// StrOrNumberArg checks that function is called with one string or numeric argument
+func (in *Interpret) StrOrNumberArg(params []Param) error {
+	if in.StrArg(params) == nil {
+		return nil
+	}
+	return in.NumberArg(params)
+}
+
 func (in *Interpret) TwoInts(params []Param) error {
 	if len(params) != 2 {
 		return fmt.Errorf("expected 2 arguments, found %v", params)
@@ -325,6 +542,21 @@ func (in *Interpret) TwoInts(params []Param) error {
 	return in.AllInts(params)
 }
 
+// TwoRationals checks that function is called with two exact numeric (integer or rational) arguments
+func (in *Interpret) TwoRationals(params []Param) error {
+	if len(params) != 2 {
+		return fmt.Errorf("expected 2 arguments, found %v", params)
+	}
+	t, err := in.Numbers(params)
+	if err != nil {
+		return err
+	}
+	if t == TypeFloat {
+		return fmt.Errorf("expected rational arguments, found %v", params)
+	}
+	return nil
+}
+
 func (in *Interpret) TwoStrs(params []Param) error {
 	if len(params) != 2 {
 		return fmt.Errorf("expected 2 arguments, found %v", params)
@@ -382,33 +614,35 @@ func (in *Interpret) ListArg(params []Param) error {
 	return nil
 }
 
-func (in *Interpret) AppenderArgs(params []Param) error {
-	if len(params) <= 1 {
-		return nil
+func (in *Interpret) ErrorArgs(params []Param) error {
+	if len(params) == 0 || len(params) > 2 {
+		return fmt.Errorf("expected one or two arguments, found %v", params)
 	}
-	ok, err := in.matchType(Type("list[a]"), params[0].T, &map[string]Type{})
-	if ok {
-		return nil
+	if len(params) == 1 {
+		if ok, err := in.canConvertType(params[0].T, TypeError); err == nil && ok {
+			return nil
+		}
 	}
+	ok, err := in.canConvertType(params[0].T, TypeStr)
 	if err != nil {
 		return err
 	}
-	ok, err = in.canConvertType(params[0].T, TypeList)
-	if err != nil {
-		return err
+	if !ok && params[0].T != TypeUnknown && !in.IsContract(params[0].T) {
+		return fmt.Errorf("expected first argument to be Str, found %v", params[0])
 	}
-	if ok {
-		return nil
+	return nil
+}
+
+func (in *Interpret) ErrorArg(params []Param) error {
+	if len(params) != 1 {
+		return fmt.Errorf("expected exaclty one argument, found %v", params)
 	}
-	ok, err = in.canConvertType(params[0].T, TypeStr)
+	ok, err := in.canConvertType(params[0].T, TypeError)
 	if err != nil {
 		return err
 	}
-	if ok {
-		return nil
-	}
-	if params[0].T != TypeUnknown && !in.IsContract(params[0].T) {
-		return fmt.Errorf("AppenderArgs: expected first argument to be Appender, found %v", params[0])
+	if !ok && params[0].T != TypeUnknown && !in.IsContract(params[0].T) {
+		return fmt.Errorf("expected argument to be Error, found %v", params[0])
 	}
 	return nil
 }
@@ -0,0 +1,209 @@
+package spil
+
+import (
+	"hash/fnv"
+	"math/bits"
+	"sort"
+)
+
+// Persistent hash array mapped trie which is used as a storage of maps and sets.
+// Every modification returns new trie sharing unchanged nodes with the old one.
+
+const (
+	hamtBits = 5
+	hamtMask = 1<<hamtBits - 1
+)
+
+type hamtEntry struct {
+	// Hash() of the key
+	hash string
+	// hash code of the key
+	code  uint64
+	key   Param
+	value Param
+}
+
+// hamtNode contains either sub-nodes or buckets of entries with the same hash code.
+type hamtNode struct {
+	bitmap uint32
+	items  []hamtItem
+}
+
+type hamtItem struct {
+	node *hamtNode
+	// entries sorted by hash
+	bucket []hamtEntry
+}
+
+type hamt struct {
+	root *hamtNode
+	size int
+}
+
+var emptyHamt = &hamt{root: &hamtNode{}}
+
+func newHamtEntry(key, value Param) (hamtEntry, error) {
+	hash, err := key.V.Hash()
+	if err != nil {
+		return hamtEntry{}, err
+	}
+	h := fnv.New64a()
+	h.Write([]byte(hash))
+	return hamtEntry{hash: hash, code: h.Sum64(), key: key, value: value}, nil
+}
+
+func (n *hamtNode) index(bit uint32) int {
+	return bits.OnesCount32(n.bitmap & (bit - 1))
+}
+
+func chunk(code uint64, depth int) uint32 {
+	return 1 << ((code >> (depth * hamtBits)) & hamtMask)
+}
+
+func (t *hamt) get(key Param) (*hamtEntry, error) {
+	e, err := newHamtEntry(key, Param{})
+	if err != nil {
+		return nil, err
+	}
+	n := t.root
+	for depth := 0; ; depth++ {
+		bit := chunk(e.code, depth)
+		if n.bitmap&bit == 0 {
+			return nil, nil
+		}
+		item := n.items[n.index(bit)]
+		if item.node != nil {
+			n = item.node
+			continue
+		}
+		for i := range item.bucket {
+			if item.bucket[i].hash == e.hash {
+				return &item.bucket[i], nil
+			}
+		}
+		return nil, nil
+	}
+}
+
+func (t *hamt) put(e hamtEntry) *hamt {
+	root, added := t.root.put(e, 0)
+	size := t.size
+	if added {
+		size++
+	}
+	return &hamt{root: root, size: size}
+}
+
+func (n *hamtNode) put(e hamtEntry, depth int) (*hamtNode, bool) {
+	bit := chunk(e.code, depth)
+	idx := n.index(bit)
+	res := &hamtNode{bitmap: n.bitmap | bit}
+	if n.bitmap&bit == 0 {
+		res.items = make([]hamtItem, 0, len(n.items)+1)
+		res.items = append(res.items, n.items[:idx]...)
+		res.items = append(res.items, hamtItem{bucket: []hamtEntry{e}})
+		res.items = append(res.items, n.items[idx:]...)
+		return res, true
+	}
+	res.items = append([]hamtItem(nil), n.items...)
+	item := n.items[idx]
+	added := false
+	switch {
+	case item.node != nil:
+		res.items[idx].node, added = item.node.put(e, depth+1)
+	case item.bucket[0].code == e.code:
+		res.items[idx].bucket, added = putEntry(item.bucket, e)
+	default:
+		// split the bucket into sub-node
+		sub := &hamtNode{}
+		for _, old := range item.bucket {
+			sub, _ = sub.put(old, depth+1)
+		}
+		res.items[idx] = hamtItem{}
+		res.items[idx].node, added = sub.put(e, depth+1)
+	}
+	return res, added
+}
+
+func putEntry(bucket []hamtEntry, e hamtEntry) ([]hamtEntry, bool) {
+	i := sort.Search(len(bucket), func(i int) bool { return bucket[i].hash >= e.hash })
+	if i < len(bucket) && bucket[i].hash == e.hash {
+		res := append([]hamtEntry(nil), bucket...)
+		res[i] = e
+		return res, false
+	}
+	res := make([]hamtEntry, 0, len(bucket)+1)
+	res = append(res, bucket[:i]...)
+	res = append(res, e)
+	res = append(res, bucket[i:]...)
+	return res, true
+}
+
+func (t *hamt) remove(key Param) (*hamt, error) {
+	e, err := newHamtEntry(key, Param{})
+	if err != nil {
+		return nil, err
+	}
+	root, removed := t.root.remove(e, 0)
+	if !removed {
+		return t, nil
+	}
+	return &hamt{root: root, size: t.size - 1}, nil
+}
+
+func (n *hamtNode) remove(e hamtEntry, depth int) (*hamtNode, bool) {
+	bit := chunk(e.code, depth)
+	if n.bitmap&bit == 0 {
+		return n, false
+	}
+	idx := n.index(bit)
+	item := n.items[idx]
+	var newItem hamtItem
+	if item.node != nil {
+		sub, removed := item.node.remove(e, depth+1)
+		if !removed {
+			return n, false
+		}
+		if sub.bitmap != 0 {
+			newItem.node = sub
+		}
+	} else {
+		i := sort.Search(len(item.bucket), func(i int) bool { return item.bucket[i].hash >= e.hash })
+		if i == len(item.bucket) || item.bucket[i].hash != e.hash {
+			return n, false
+		}
+		newItem.bucket = append(append([]hamtEntry(nil), item.bucket[:i]...), item.bucket[i+1:]...)
+	}
+	res := &hamtNode{bitmap: n.bitmap}
+	if newItem.node == nil && len(newItem.bucket) == 0 {
+		res.bitmap &^= bit
+		res.items = append(append([]hamtItem(nil), n.items[:idx]...), n.items[idx+1:]...)
+	} else {
+		res.items = append([]hamtItem(nil), n.items...)
+		res.items[idx] = newItem
+	}
+	return res, true
+}
+
+// each calls fn for every entry of the trie.
+// Order of entries depends only on keys and not on the history of modifications.
+func (t *hamt) each(fn func(e *hamtEntry) error) error {
+	return t.root.each(fn)
+}
+
+func (n *hamtNode) each(fn func(e *hamtEntry) error) error {
+	for _, item := range n.items {
+		if item.node != nil {
+			if err := item.node.each(fn); err != nil {
+				return err
+			}
+			continue
+		}
+		for i := range item.bucket {
+			if err := fn(&item.bucket[i]); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,141 @@
+package spil
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Local type inference.
+//
+// Implementations without declared return type are instantiated at each call site:
+// untyped arguments get types of the actual parameters (like type variables of generic functions)
+// and return type is inferred from the body. Results are cached per implementation and types of parameters.
+// Recursive calls are resolved by iteration: the first pass sees recursive calls as :unknown,
+// the next passes use type inferred by the previous one until it does not change.
+
+const maxInferPasses = 3
+
+// inferReturnType returns type of implementation idx of fi called with params.
+func (in *Interpret) inferReturnType(fi *FuncInterpret, idx int, params []Param) (Type, error) {
+	im := fi.bodies[idx]
+	if im.argfmt == nil {
+		return TypeUnknown, nil
+	}
+	values := im.argfmt.Values()
+	key := &strings.Builder{}
+	fmt.Fprintf(key, "%p", im)
+	if im.argfmt.Wildcard == "" {
+		for i, arg := range im.argfmt.Args {
+			// values of type :any are checked at runtime
+			known := i < len(params) && params[i].T != "" && params[i].T != TypeAny
+			if p := arg.Pattern; p != nil && p.Variant == "" {
+				// element type of the list flows into untyped variables of the pattern
+				if known {
+					in.listPatternTypes(values, p, params[i].T)
+					key.WriteString(" " + params[i].T.String())
+				} else {
+					key.WriteString(" " + arg.T.String())
+				}
+				continue
+			}
+			if arg.Pattern == nil && arg.V == nil && arg.Name != "" && arg.T == TypeUnknown && known {
+				values[arg.Name] = params[i].T
+			}
+			key.WriteString(" " + values[arg.Name].String())
+		}
+	}
+	if t, ok := in.inferred[key.String()]; ok {
+		return t, nil
+	}
+	// recursive calls see the type inferred so far
+	in.inferred[key.String()] = TypeUnknown
+	t := TypeUnknown
+	for pass := 0; pass < maxInferPasses; pass++ {
+		scope := make(map[string]Type, len(values))
+		for name, t := range values {
+			scope[name] = t
+		}
+		nt, err := in.evalBodyType(fi.name, im.body, scope, nil)
+		if err != nil {
+			delete(in.inferred, key.String())
+			return TypeUnknown, err
+		}
+		if nt == t {
+			break
+		}
+		if pass > 0 {
+			nt = in.joinTypes(t, nt)
+		}
+		t = nt
+		in.inferred[key.String()] = t
+		if t == TypeUnknown {
+			break
+		}
+	}
+	return t, nil
+}
+
+// shortLambdaType infers type of lambda with implicit arguments _1, _2 ...:
+// arguments are :unknown and return type is the type of its body.
+// :func is returned if return type cannot be inferred.
+func (in *Interpret) shortLambdaType(fname string, body []Param, vars map[string]Type) (Type, error) {
+	arity, ok := implicitArity(body)
+	if !ok {
+		return TypeFunc, nil
+	}
+	scope := make(map[string]Type, len(vars)+arity)
+	for name, t := range vars {
+		scope[name] = t
+	}
+	// recursive call of lambda
+	scope["self"] = TypeFunc
+	for i := 1; i <= arity; i++ {
+		scope["_"+strconv.Itoa(i)] = TypeUnknown
+	}
+	rt, err := in.evalBodyType(fname, body, scope, nil)
+	if err != nil {
+		return TypeUnknown, err
+	}
+	if rt == TypeUnknown || rt == TypeAny || in.IsGeneric(rt) {
+		return TypeFunc, nil
+	}
+	args := make([]string, 0, arity+1)
+	for i := 0; i < arity; i++ {
+		args = append(args, string(TypeUnknown))
+	}
+	args = append(args, string(rt))
+	return Type("func[" + strings.Join(args, ",") + "]"), nil
+}
+
+// implicitArity returns the number of implicit arguments used in the body of lambda.
+// It returns false if body uses __args.
+func implicitArity(body []Param) (int, bool) {
+	arity := 0
+	for _, p := range body {
+		switch a := p.V.(type) {
+		case Ident:
+			if a == "__args" {
+				return 0, false
+			}
+			if reArg.MatchString(string(a)) {
+				if n, _ := strconv.Atoi(string(a[1:])); n > arity {
+					arity = n
+				}
+			}
+		case *Sexpr:
+			if a.Quoted || a.Lambda || (!a.Empty() && a.List[0].V == Ident("lambda")) {
+				// nested lambda has its own arguments
+				continue
+			}
+			n, ok := implicitArity(a.List)
+			if !ok {
+				return 0, false
+			}
+			if n > arity {
+				arity = n
+			}
+		}
+	}
+	return arity, true
+}
@@ -0,0 +1,41 @@
+package spil
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+func TestInference(t *testing.T) {
+	onlyInt := "(def only-int (x:int) :int x)\n"
+	tests := []programTest{
+		{"untyped argument", onlyInt + "(def sq (x) (* x x))\n(print (only-int (sq 3)))", "9\n", ""},
+		{"untyped argument mismatch", onlyInt + "(def sq (x) (* x x))\n(print (only-int (sq 1.5)))", "", "only-int: no matching function implementation found for [{:float <nil>}]"},
+		{"body mismatch", "(def f (x) (+ x 1))\n(print (f \"a\"))", "", "f: +: Expected all numeric arguments, found {:str <nil>} at position 0"},
+		{"nested calls", "(def f (x) (+ x 1))\n(def g (y) (f y))\n(print (g \"a\"))", "", "g: f: +: Expected all numeric arguments"},
+		{"typed arguments", "(use std)\n" + onlyInt + "(def f (x:str) (length x))\n(print (only-int (f \"abc\")))", "3\n", ""},
+		{"recursion", onlyInt + "(def fib (n) (if (< n 2) n (+ (fib (- n 1)) (fib (- n 2)))))\n(print (only-int (fib 10)))", "55\n", ""},
+		{"clauses", onlyInt + "(def fact (0) 1)\n(def fact (n) (* n (fact (- n 1))))\n(print (only-int (fact 5)))", "120\n", ""},
+		{"generic", "(use std)\n" + onlyInt + "(def snd (l) (second l))\n(print (only-int (snd (do '(1 2) :list[int]))))", "2\n", ""},
+		{"any is dynamic", "(def f (x) (< x 10))\n(print (f (do 5 :any)))", "true\n", ""},
+		{"short lambda", onlyInt + "(set f \\(+ _1 1))\n(print (only-int (f 2)))", "3\n", ""},
+		{"short lambda mismatch", onlyInt + "(set f (lambda (str _1)))\n(print (only-int (f 2)))", "", "only-int: no matching function implementation found for [{:str <nil>}]"},
+	}
+	checkPrograms(t, tests)
+}
+
+func TestInferenceErrorsAreReported(t *testing.T) {
+	// the failed inference of f should not be cached: both call sites are reported
+	input := "(def f (x) (+ x 1))\n(def g () :int (f \"a\"))\n(def h () :int (f \"a\"))\n"
+	in := NewInterpreter(ioutil.Discard, getTestLibraryDir())
+	if err := in.Parse("test.lisp", strings.NewReader(input)); err != nil {
+		t.Fatalf("Parse() failed: %v", err)
+	}
+	errs := fmt.Sprint(in.Check())
+	for _, fn := range []string{"g", "h"} {
+		if exp := fn + ": f: +: Expected all numeric arguments"; !strings.Contains(errs, exp) {
+			t.Errorf("Expected error %q, actual errors: %v", exp, errs)
+		}
+	}
+}
@@ -0,0 +1,266 @@
+package spil
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"strconv"
+)
+
+type Int interface {
+	Expr
+	Plus(Int) Int
+	Minus(Int) Int
+	Mult(Int) Int
+	Div(Int) Int
+	Mod(Int) Int
+	Less(Int) bool
+	Eq(Int) bool
+	Int64() int64
+}
+
+type IntMaker interface {
+	ParseInt(token string) (Int, bool)
+	MakeInt(i int64) Int
+}
+
+type Int64 int64
+
+var _ Int = Int64(0)
+
+type Int64Maker struct{}
+
+var _ IntMaker = Int64Maker{}
+
+// ParseInt parses int64 integers. Integers which do not fit into int64 are parsed as big integers.
+func (i Int64Maker) ParseInt(token string) (Int, bool) {
+	n, err := strconv.ParseInt(token, 10, 64)
+	if errors.Is(err, strconv.ErrRange) {
+		res, ok := (&big.Int{}).SetString(token, 10)
+		if !ok {
+			return nil, false
+		}
+		return &BigInt{value: res, auto: true}, true
+	}
+	if err != nil {
+		return nil, false
+	}
+	return Int64(n), true
+}
+
+func (Int64Maker) MakeInt(i int64) Int {
+	return Int64(i)
+}
+
+func (i Int64) String() string {
+	return fmt.Sprintf("{Int64: %d}", int64(i))
+}
+func (i Int64) Hash() (string, error) {
+	return i.String(), nil
+}
+
+func (i Int64) Print(w io.Writer) {
+	fmt.Fprintf(w, "%d", int64(i))
+}
+
+// Arithmetic operations on Int64 are promoted to big integers on overflow.
+
+func (i Int64) Plus(a Int) Int {
+	b, ok := a.(Int64)
+	if !ok {
+		return i.big().Plus(a)
+	}
+	s := i + b
+	if (b > 0 && s < i) || (b < 0 && s > i) {
+		return i.big().Plus(b)
+	}
+	return s
+}
+
+func (i Int64) Minus(a Int) Int {
+	b, ok := a.(Int64)
+	if !ok {
+		return i.big().Minus(a)
+	}
+	s := i - b
+	if (b > 0 && s > i) || (b < 0 && s < i) {
+		return i.big().Minus(b)
+	}
+	return s
+}
+
+func (i Int64) Mult(a Int) Int {
+	b, ok := a.(Int64)
+	if !ok {
+		return i.big().Mult(a)
+	}
+	if i == 0 || b == 0 {
+		return Int64(0)
+	}
+	p := i * b
+	if p/b != i || (i == -1 && b == math.MinInt64) || (b == -1 && i == math.MinInt64) {
+		return i.big().Mult(b)
+	}
+	return p
+}
+
+func (i Int64) Div(a Int) Int {
+	b, ok := a.(Int64)
+	if !ok || (i == math.MinInt64 && b == -1) {
+		return i.big().Div(a)
+	}
+	return Int64(i / b)
+}
+
+func (i Int64) Mod(a Int) Int {
+	b, ok := a.(Int64)
+	if !ok {
+		return i.big().Mod(a)
+	}
+	return Int64(i % b)
+}
+
+func (i Int64) Less(a Int) bool {
+	b, ok := a.(Int64)
+	if !ok {
+		return i.big().Less(a)
+	}
+	return i < b
+}
+
+func (i Int64) Eq(a Int) bool {
+	b, ok := a.(Int64)
+	if !ok {
+		return i.big().Eq(a)
+	}
+	return i == b
+}
+
+func (i Int64) Type() Type {
+	return TypeInt
+}
+
+func (i Int64) Int64() int64 {
+	return int64(i)
+}
+
+// big converts value into big integer which is demoted back to Int64 when it gets small.
+func (i Int64) big() *BigInt {
+	return &BigInt{value: big.NewInt(int64(i)), auto: true}
+}
+
+type BigInt struct {
+	value *big.Int
+	// auto is set for big integers which appeared as a result of Int64 overflow.
+	// Results of operations on such integers are converted into Int64 if they fit.
+	auto bool
+}
+
+var _ Int = (*BigInt)(nil)
+
+type BigIntMaker struct{}
+
+var _ IntMaker = BigIntMaker{}
+
+func (BigIntMaker) ParseInt(token string) (Int, bool) {
+	res := &big.Int{}
+	_, ok := res.SetString(token, 10)
+	if !ok {
+		return nil, false
+	}
+	return &BigInt{value: res}, true
+}
+
+func (BigIntMaker) MakeInt(i int64) Int {
+	return &BigInt{value: big.NewInt(i)}
+}
+
+func (i *BigInt) String() string {
+	return fmt.Sprintf("{BigInt: %v}", i.value)
+}
+
+func (i *BigInt) Hash() (string, error) {
+	return i.String(), nil
+}
+
+func (i *BigInt) Print(w io.Writer) {
+	fmt.Fprintf(w, "%v", i.value)
+}
+
+func (i *BigInt) Plus(a Int) Int {
+	b, auto := i.arg(a)
+	return i.result(new(big.Int).Add(i.value, b), auto)
+}
+
+func (i *BigInt) Minus(a Int) Int {
+	b, auto := i.arg(a)
+	return i.result(new(big.Int).Sub(i.value, b), auto)
+}
+
+func (i *BigInt) Mult(a Int) Int {
+	b, auto := i.arg(a)
+	return i.result(new(big.Int).Mul(i.value, b), auto)
+}
+
+func (i *BigInt) Div(a Int) Int {
+	b, auto := i.arg(a)
+	if auto {
+		// truncated division to keep results consistent with Int64
+		return i.result(new(big.Int).Quo(i.value, b), auto)
+	}
+	return i.result(new(big.Int).Div(i.value, b), auto)
+}
+
+func (i *BigInt) Mod(a Int) Int {
+	b, auto := i.arg(a)
+	if auto {
+		return i.result(new(big.Int).Rem(i.value, b), auto)
+	}
+	return i.result(new(big.Int).Mod(i.value, b), auto)
+}
+
+func (i *BigInt) Less(a Int) bool {
+	b, _ := i.arg(a)
+	return i.value.Cmp(b) < 0
+}
+
+func (i *BigInt) Eq(a Int) bool {
+	b, _ := i.arg(a)
+	return i.value.Cmp(b) == 0
+}
+
+func (i *BigInt) Type() Type {
+	return TypeInt
+}
+
+func (i *BigInt) Int64() int64 {
+	return i.value.Int64()
+}
+
+// arg returns value of the argument as big.Int
+// and reports if the operation result should be demoted to Int64.
+func (i *BigInt) arg(a Int) (*big.Int, bool) {
+	switch b := a.(type) {
+	case *BigInt:
+		return b.value, i.auto || b.auto
+	case Int64:
+		return big.NewInt(int64(b)), true
+	}
+	return big.NewInt(a.Int64()), i.auto
+}
+
+func (i *BigInt) result(v *big.Int, auto bool) Int {
+	if auto && v.IsInt64() {
+		return Int64(v.Int64())
+	}
+	return &BigInt{value: v, auto: auto}
+}
+
+func isZero(i Int) bool {
+	if b, ok := i.(*BigInt); ok {
+		return b.value.Sign() == 0
+	}
+	return i.Int64() == 0
+}
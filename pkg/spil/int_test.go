@@ -0,0 +1,71 @@
+package spil
+
+import (
+	"math"
+	"math/big"
+	"testing"
+)
+
+func bigFromString(s string) *big.Int {
+	res, ok := new(big.Int).SetString(s, 10)
+	if !ok {
+		panic(s)
+	}
+	return res
+}
+
+func TestInt64Overflow(t *testing.T) {
+	tests := []struct {
+		name string
+		op   func() Int
+		exp  string
+		big  bool
+	}{
+		{"plus", func() Int { return Int64(math.MaxInt64).Plus(Int64(1)) }, "9223372036854775808", true},
+		{"plus negative", func() Int { return Int64(math.MinInt64).Plus(Int64(-1)) }, "-9223372036854775809", true},
+		{"plus no overflow", func() Int { return Int64(math.MaxInt64).Plus(Int64(-1)) }, "9223372036854775806", false},
+		{"minus", func() Int { return Int64(math.MinInt64).Minus(Int64(1)) }, "-9223372036854775809", true},
+		{"minus negative", func() Int { return Int64(math.MaxInt64).Minus(Int64(-1)) }, "9223372036854775808", true},
+		{"mult", func() Int { return Int64(1 << 62).Mult(Int64(4)) }, "18446744073709551616", true},
+		{"mult min", func() Int { return Int64(math.MinInt64).Mult(Int64(-1)) }, "9223372036854775808", true},
+		{"mult min (2)", func() Int { return Int64(-1).Mult(Int64(math.MinInt64)) }, "9223372036854775808", true},
+		{"mult no overflow", func() Int { return Int64(-3).Mult(Int64(7)) }, "-21", false},
+		{"div", func() Int { return Int64(math.MinInt64).Div(Int64(-1)) }, "9223372036854775808", true},
+		{"demote", func() Int { return Int64(math.MaxInt64).Plus(Int64(1)).Minus(Int64(2)) }, "9223372036854775806", false},
+		{"demote (2)", func() Int { return Int64(2).Minus(Int64(math.MaxInt64).Plus(Int64(1))) }, "-9223372036854775806", false},
+		{"truncated div", func() Int { return Int64(math.MaxInt64).Plus(Int64(2)).Div(Int64(-2)) }, "-4611686018427387904", false},
+		{"truncated mod", func() Int { return Int64(math.MinInt64).Minus(Int64(1)).Mod(Int64(10)) }, "-9", false},
+		{"mixed", func() Int { return Int64(1).Plus(&BigInt{value: bigFromString("100000000000000000000")}) }, "100000000000000000001", true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			res := test.op()
+			if _, isBig := res.(*BigInt); isBig != test.big {
+				t.Errorf("Incorrect result representation: %v", res)
+			}
+			if act := toBigInt(res).String(); act != test.exp {
+				t.Errorf("Incorrect result: expected %v, actual %v", test.exp, act)
+			}
+		})
+	}
+}
+
+func TestParseBigLiteral(t *testing.T) {
+	i, ok := Int64Maker{}.ParseInt("100000000000000000000")
+	if !ok {
+		t.Fatalf("ParseInt failed")
+	}
+	if _, ok := i.(*BigInt); !ok {
+		t.Errorf("Big integer literal expected, found %v", i)
+	}
+	if !i.Eq(Int64(1).Mult(Int64(100000000000)).Mult(Int64(1000000000))) {
+		t.Errorf("Incorrect value of big integer literal: %v", i)
+	}
+}
+
+func toBigInt(i Int) *big.Int {
+	if b, ok := i.(*BigInt); ok {
+		return b.value
+	}
+	return big.NewInt(i.Int64())
+}
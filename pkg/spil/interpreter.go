@@ -0,0 +1,1362 @@
+package spil
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"math/big"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+type Interpret struct {
+	output      io.Writer
+	funcs       map[string]Evaler
+	types       map[Type]Type
+	typeAliases map[Type]Type
+	// cache of parsed types: see typeExpr
+	typeExprs map[Type]*TypeExpr
+	contracts map[Type]struct{}
+	// functions required by contracts
+	contractFuncs map[Type][]contractFunc
+	// inferred return types of implementations: see inferReturnType
+	inferred map[string]Type
+	// bindings of type variables of generic implementation which is being checked
+	typeBinds map[string]Type
+	// generic implementations which are being checked (recursive calls are not checked again)
+	rechecking map[*FuncImpl]bool
+	mainBody   []Param
+
+	// algebraic data types: type -> variants
+	unions   map[Type][]*recordDef
+	variants map[Type]*recordDef
+
+	// non-fatal problems found by Check
+	warnings []error
+
+	libraryDir string
+
+	intMaker IntMaker
+
+	lambdaCount int
+	gensymCount int
+
+	// user-defined macros expanded at parse time
+	macros map[string]*FuncInterpret
+
+	strictTypes bool
+
+	main *FuncInterpret
+
+	// native modules registered by host application
+	modules       map[string]Module
+	loadedModules map[string]bool
+}
+
+func NewInterpreter(w io.Writer, libraryDir string) *Interpret {
+	i := &Interpret{
+		output:        w,
+		libraryDir:    libraryDir,
+		intMaker:      &Int64Maker{},
+		contracts:     make(map[Type]struct{}),
+		contractFuncs: make(map[Type][]contractFunc),
+		inferred:      make(map[string]Type),
+		rechecking:    make(map[*FuncImpl]bool),
+		typeExprs:     make(map[Type]*TypeExpr),
+		unions:        make(map[Type][]*recordDef),
+		variants:      make(map[Type]*recordDef),
+		macros:        make(map[string]*FuncInterpret),
+
+		modules:       make(map[string]Module),
+		loadedModules: make(map[string]bool),
+	}
+	i.funcs = map[string]Evaler{
+		"+":                    TypedEvalerFunc("+", FPlus, i.Numbers, TypeFloat),
+		"-":                    TypedEvalerFunc("-", FMinus, i.Numbers, TypeFloat),
+		"*":                    TypedEvalerFunc("*", FMultiply, i.Numbers, TypeFloat),
+		"/":                    TypedEvalerFunc("/", FDiv, i.Numbers, TypeFloat),
+		"mod":                  EvalerFunc("mod", FMod, i.TwoInts, TypeInt),
+		"native.int.less":      EvalerFunc("native.int.less", FIntLess, i.TwoInts, TypeBool),
+		"native.str.less":      EvalerFunc("native.str.less", FStrLess, i.TwoStrs, TypeBool),
+		"native.float.less":    EvalerFunc("native.float.less", FFloatLess, i.TwoNumbers, TypeBool),
+		"native.rational.less": EvalerFunc("native.rational.less", FRationalLess, i.TwoRationals, TypeBool),
+		"=":                    EvalerFunc("=", FEq, TwoArgs, TypeBool),
+		"not":                  EvalerFunc("not", FNot, i.OneBoolArg, TypeBool),
+		"print":                EvalerFunc("print", i.FPrint, AnyArgs, TypeAny),
+		"native.head":          EvalerFunc("native.head", FHead, AnyArgs, TypeAny),
+		"native.tail":          EvalerFunc("native.tail", FTail, AnyArgs, TypeList),
+		"append":               TypedEvalerFunc("append", i.FAppend, i.AppendArgs, TypeList),
+		"list":                 TypedEvalerFunc("list", i.FList, i.ListArgs, TypeList),
+		"space":                EvalerFunc("space", FSpace, i.StrArg, TypeBool),
+		"eol":                  EvalerFunc("eol", FEol, i.StrArg, TypeBool),
+		"empty":                EvalerFunc("empty", FEmpty, i.ListArg, TypeBool),
+		"native.length":        EvalerFunc("native.length", i.FLength, i.ListArg, TypeInt),
+		"native.nth":           EvalerFunc("native.nth", i.FNth, i.IntAndListArgs, TypeAny),
+		"int":                  EvalerFunc("int", i.FInt, i.StrOrNumberArg, TypeInt),
+		"float":                EvalerFunc("float", FFloat, i.StrOrNumberArg, TypeFloat),
+		"str":                  EvalerFunc("str", FStr, SingleArg, TypeStr),
+		"open":                 EvalerFunc("open", FOpen, i.StrArg, TypeStr),
+		"type":                 EvalerFunc("type", FType, SingleArg, TypeStr),
+		"gensym":               EvalerFunc("gensym", i.FGensym, NoArgs, TypeAny),
+		"error":                EvalerFunc("error", FError, i.ErrorArgs, TypeNothing),
+		"error-message":        EvalerFunc("error-message", FErrorMessage, i.ErrorArg, TypeStr),
+		"error-payload":        EvalerFunc("error-payload", FErrorPayload, i.ErrorArg, TypeAny),
+		"map-of":               TypedEvalerFunc("map-of", i.FMapOf, i.MapOfArgs, TypeMap),
+		"map-get":              TypedEvalerFunc("map-get", i.FMapGet, i.MapGetArgs, TypeAny),
+		"map-put":              TypedEvalerFunc("map-put", i.FMapPut, i.MapPutArgs, TypeMap),
+		"map-remove":           TypedEvalerFunc("map-remove", i.FMapRemove, i.MapKeyArgs, TypeMap),
+		"map-contains":         TypedEvalerFunc("map-contains", i.FMapContains, i.MapContainsArgs, TypeBool),
+		"map-keys":             TypedEvalerFunc("map-keys", i.FMapKeys, i.MapKeysArgs, TypeList),
+		"map-values":           TypedEvalerFunc("map-values", i.FMapValues, i.MapValuesArgs, TypeList),
+		"map-items":            TypedEvalerFunc("map-items", i.FMapItems, i.MapItemsArgs, TypeList),
+		"map-size":             EvalerFunc("map-size", i.FMapSize, i.MapSizeArgs, TypeInt),
+		"vector-of":            TypedEvalerFunc("vector-of", i.FVectorOf, i.VectorOfArgs, TypeVector),
+		"list->vector":         TypedEvalerFunc("list->vector", i.FListToVector, i.ListToVectorArgs, TypeVector),
+		"vector->list":         TypedEvalerFunc("vector->list", i.FVectorToList, i.VectorToListArgs, TypeList),
+		"vector-nth":           TypedEvalerFunc("vector-nth", i.FVectorNth, i.VectorNthArgs, TypeAny),
+		"vector-assoc":         TypedEvalerFunc("vector-assoc", i.FVectorAssoc, i.VectorAssocArgs, TypeVector),
+		"vector-push":          TypedEvalerFunc("vector-push", i.FVectorPush, i.VectorPushArgs, TypeVector),
+		"vector-slice":         TypedEvalerFunc("vector-slice", i.FVectorSlice, i.VectorSliceArgs, TypeVector),
+		"vector-length":        EvalerFunc("vector-length", i.FVectorLength, i.VectorLengthArgs, TypeInt),
+		"set-of":               TypedEvalerFunc("set-of", i.FSetOf, i.SetOfArgs, TypeAny),
+		"list->set":            TypedEvalerFunc("list->set", i.FListToSet, i.ListToSetArgs, TypeAny),
+		"set-insert":           TypedEvalerFunc("set-insert", i.FSetInsert, i.SetElemArgs, TypeAny),
+		"set-remove":           TypedEvalerFunc("set-remove", i.FSetRemove, i.SetElemArgs, TypeAny),
+		"set-contains":         TypedEvalerFunc("set-contains", i.FSetContains, i.SetContainsArgs, TypeBool),
+		"set-union":            TypedEvalerFunc("set-union", i.FSetUnion, i.SetUnionArgs, TypeAny),
+		"set-intersection":     TypedEvalerFunc("set-intersection", i.FSetIntersection, i.SetsArgs, TypeAny),
+		"set-difference":       TypedEvalerFunc("set-difference", i.FSetDifference, i.SetsArgs, TypeAny),
+		"set-size":             EvalerFunc("set-size", i.FSetSize, i.SetSizeArgs, TypeInt),
+		"set-items":            TypedEvalerFunc("set-items", i.FSetItems, i.SetItemsArgs, TypeList),
+	}
+	i.types = map[Type]Type{
+		TypeUnknown:  "",
+		TypeAny:      "",
+		TypeInt:      TypeRational,
+		TypeRational: TypeFloat,
+		TypeFloat:    TypeAny,
+		TypeStr:      "list[str]",
+		TypeBool:     TypeAny,
+		TypeFunc:     TypeAny,
+		TypeError:    TypeAny,
+		TypeNothing:  "",
+		"list[a]":    TypeAny,
+		"map[a,b]":   TypeAny,
+		"vector[a]":  "list[a]",
+		"set[a]":     TypeAny,
+	}
+	i.typeAliases = map[Type]Type{
+		TypeList:   "list[any]",
+		TypeMap:    "map[any,any]",
+		TypeVector: "vector[any]",
+	}
+	return i
+}
+
+func (i *Interpret) UseBigInt(v bool) {
+	if v {
+		i.intMaker = &BigIntMaker{}
+	} else {
+		i.intMaker = &Int64Maker{}
+	}
+}
+
+func (i *Interpret) loadLibrary(dir string) error {
+	files, err := filepath.Glob(filepath.Join(dir, "*.lisp"))
+	if err != nil {
+		return fmt.Errorf("Error while loading builtins: %w", err)
+	}
+	if len(files) == 0 {
+		return fmt.Errorf("Builtin source files not found in %v", dir)
+	}
+	for _, file := range files {
+		err := func() error {
+			f, err := os.Open(file)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			absPath, err := filepath.Abs(file)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Cannot determine absolute path for %q: %e", file, err)
+				absPath = file
+			}
+			if err := i.parse(absPath, f); err != nil {
+				return err
+			}
+			return nil
+		}()
+		if err != nil {
+			return prefixErr(fmt.Sprintf("Error whire loading %v", file), err)
+		}
+	}
+	return nil
+}
+
+func (i *Interpret) ParseInt(token string) (Int, bool) {
+	return i.intMaker.ParseInt(token)
+}
+
+func (i *Interpret) parse(file string, input io.Reader) error {
+	parser := NewParser(input, i)
+	parser.file = file
+L:
+	for {
+		val, err := parser.NextExpr()
+		if err == io.EOF {
+			break L
+		}
+		if err != nil {
+			return err
+		}
+		if se, ok := val.V.(*Sexpr); ok && !se.Quoted && se.Length() > 0 && se.List[0].V == Ident("defmacro") {
+			tail, _ := se.Tail()
+			if err := i.defineMacro(tail.(*Sexpr)); err != nil {
+				return withPos(val.Pos, err)
+			}
+			continue L
+		}
+		expanded, err := i.expandMacros(*val)
+		if err != nil {
+			return err
+		}
+		val = &expanded
+		switch a := val.V.(type) {
+		case *Sexpr:
+			if a.Quoted {
+				return withPos(val.Pos, fmt.Errorf("Unexpected quoted s-expression: %v", a))
+			}
+			if a.Length() == 0 {
+				return withPos(val.Pos, fmt.Errorf("Unexpected empty s-expression on top-level: %v", a))
+			}
+			head, _ := a.Head()
+			if name, ok := head.V.(Ident); ok {
+				switch name {
+				case "func", "def", "func'", "def'":
+					memo := false
+					if name == "func'" || name == "def'" {
+						memo = true
+					}
+					tail, _ := a.Tail()
+					if err := i.defineFunc(file, tail.(*Sexpr), memo, val.Pos); err != nil {
+						return withPos(val.Pos, err)
+					}
+					continue L
+				case "use":
+					tail, _ := a.Tail()
+					if err := i.use(tail.(*Sexpr).List); err != nil {
+						return withPos(val.Pos, err)
+					}
+					continue L
+				case "deftype":
+					tail, _ := a.Tail()
+					if err := i.defineType(tail.(*Sexpr).List); err != nil {
+						return withPos(val.Pos, err)
+					}
+					continue L
+				case "defrecord":
+					tail, _ := a.Tail()
+					if err := i.defineRecord(tail.(*Sexpr).List); err != nil {
+						return withPos(val.Pos, err)
+					}
+					continue L
+				case "defunion":
+					tail, _ := a.Tail()
+					if err := i.defineUnion(tail.(*Sexpr).List); err != nil {
+						return withPos(val.Pos, err)
+					}
+					continue L
+				case "contract":
+					tail, _ := a.Tail()
+					if err := i.defineContract(tail.(*Sexpr).List); err != nil {
+						return withPos(val.Pos, err)
+					}
+					continue L
+				}
+			}
+		}
+		i.mainBody = append(i.mainBody, *val)
+	}
+	return nil
+}
+
+func (i *Interpret) Parse(file string, input io.Reader) error {
+	if err := i.loadLibrary(filepath.Join(i.libraryDir, "builtin")); err != nil {
+		return err
+	}
+
+	if err := i.parse(file, input); err != nil {
+		return err
+	}
+
+	i.main = NewFuncInterpret(i, "__main__")
+	if err := i.main.AddImpl(Ident("__main_args"), i.mainBody, false, TypeAny); err != nil {
+		return err
+	}
+	return nil
+}
+
+// type-checking
+func (i *Interpret) Check() []error {
+	errs := i.CheckReturnTypes()
+	// non-exhaustive patterns are errors only in strict mode
+	i.warnings = i.checkPatterns()
+	if i.strictTypes {
+		errs = append(errs, i.warnings...)
+		i.warnings = nil
+	}
+	return errs
+}
+
+// Warnings returns non-fatal problems found by the last Check.
+func (i *Interpret) Warnings() []error {
+	return i.warnings
+}
+
+// Run evaluates the main body of the parsed program.
+// stdin is available to the program as __stdin, args are bound to _1, _2 ... and __args.
+func (i *Interpret) Run(stdin io.Reader, args []string) error {
+	if stdin == nil {
+		stdin = strings.NewReader("")
+	}
+	i.main.capturedVars["__stdin"] = &Param{V: NewLazyInput(ioutil.NopCloser(stdin)), T: TypeStr}
+	params := make([]Param, 0, len(args))
+	for _, arg := range args {
+		params = append(params, Param{V: Str(arg), T: TypeStr})
+	}
+	_, err := i.main.Eval(params)
+	return err
+}
+
+// (func-name) args body...
+func (i *Interpret) defineFunc(file string, se *Sexpr, memo bool, pos *Pos) error {
+	if se.Length() < 3 {
+		return fmt.Errorf("Not enough arguments for function definition: %v", se)
+	}
+	name, ok := se.List[0].V.(Ident)
+	if !ok {
+		return fmt.Errorf("func expected identifier first, found %v", se.List[0])
+	}
+
+	fname := string(name)
+	if _, ok := i.macros[fname]; ok {
+		return fmt.Errorf("Cannot define function %v: macro with the same name already exists", fname)
+	}
+	var fi *FuncInterpret
+
+	evaler, ok := i.funcs[fname]
+	if ok {
+		f, ok := evaler.(*FuncInterpret)
+		if !ok {
+			// builtin function is called when none of user implementations matches
+			f = NewFuncInterpret(i, fname)
+			f.native = evaler
+			i.funcs[fname] = f
+		}
+		fi = f
+	} else {
+		fi = NewFuncInterpret(i, fname)
+		i.funcs[fname] = fi
+	}
+	bodyIndex := 2
+	returnType := TypeUnknown
+	// Check if return type is specified
+	if identType, ok := se.List[2].V.(Ident); ok {
+		returnType, ok = ParseType(string(identType))
+		if ok {
+			if _, err := i.parseType(string(identType)); err != nil {
+				return fmt.Errorf("%v: %v", fname, err)
+			}
+			bodyIndex++
+		}
+	}
+	body := se.List[2:]
+	// Check if guard is specified: (def f (args) :type when (guard-expr) body...)
+	var guard *Param
+	if len(se.List) > bodyIndex+2 && se.List[bodyIndex].V == Ident("when") {
+		guard = &se.List[bodyIndex+1]
+		body = append(append([]Param(nil), se.List[2:bodyIndex]...), se.List[bodyIndex+2:]...)
+	}
+	// TODO
+	if err := fi.AddImpl(se.List[1].V, body, memo, returnType); err != nil {
+		return err
+	}
+	impl := fi.bodies[len(fi.bodies)-1]
+	impl.pos = pos
+	impl.guard = guard
+	// Functions may be extended with implementations from other files (e.g. for user-defined types)
+	// but they should not replace existing ones.
+	for _, im := range fi.bodies[:len(fi.bodies)-1] {
+		if im.pos != nil && im.pos.File != file && im.guard == nil && guard == nil && i.sameSignature(im.argfmt, impl.argfmt) {
+			fi.bodies = fi.bodies[:len(fi.bodies)-1]
+			return fmt.Errorf("cannot define function '%v' %v in file %v: it is already defined in %v", fname, impl.argfmt, file, im.pos.File)
+		}
+	}
+	return nil
+}
+
+func (i *Interpret) use(args []Param) error {
+	if len(args) != 1 {
+		return fmt.Errorf("'use' expected one argument, found: %v", args)
+	}
+	module := args[0]
+	switch a := module.V.(type) {
+	case Str:
+		f, err := os.Open(string(a))
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		fpath, err := filepath.Abs(string(a))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Cannot detect absolute path for %v: %v\n", string(a), err)
+			fpath = string(a)
+		}
+		return i.parse(fpath, f)
+	case Ident:
+		switch string(a) {
+		case "bigmath":
+			i.UseBigInt(true)
+		case "std":
+			if err := i.loadLibrary(filepath.Join(i.libraryDir, "std")); err != nil {
+				return err
+			}
+		case "strict":
+			i.strictTypes = true
+		case "rational":
+			i.useRational()
+		default:
+			ok, err := i.loadModule(string(a))
+			if err != nil {
+				return err
+			}
+			if !ok {
+				return fmt.Errorf("Unknown use-directive: %v", string(a))
+			}
+		}
+		return nil
+	}
+	return fmt.Errorf("Unexpected argument type to 'use': %v (%T)", module, module)
+}
+
+// redefinableTypes are builtin types which may be shadowed by user types defined with deftype
+// (e.g. :set[a] is often defined as a list). Builtin functions of such types should not be used then.
+var redefinableTypes = map[Type]bool{
+	"set[a]": true,
+}
+
+// (new-type) (old-type)
+func (in *Interpret) defineType(args []Param) error {
+	if len(args) != 2 {
+		return fmt.Errorf("'deftype' expected two arguments, found: %v", args)
+	}
+	newId, ok := args[0].V.(Ident)
+	if !ok {
+		return fmt.Errorf("deftype expects first argument to be new type, found: %v", args[0])
+	}
+	newType, ok := ParseType(string(newId))
+	if !ok {
+		return fmt.Errorf("deftype expects first argument to be new type, found: %v", args[0])
+	}
+
+	if parent, ok := in.types[newType.Canonical()]; ok && (!redefinableTypes[newType.Canonical()] || parent != TypeAny) {
+		return fmt.Errorf("Cannot redefine type %v", newType)
+	}
+
+	oldId, ok := args[1].V.(Ident)
+	if !ok {
+		return fmt.Errorf("deftype expects first argument to be new type, found: %v", args[1])
+	}
+	oldType, ok := ParseType(string(oldId))
+	if !ok {
+		return fmt.Errorf("deftype expects first argument to be new type, found: %v", args[0])
+	}
+	for _, t := range []Type{newType, oldType} {
+		if _, err := ParseTypeExpr(string(t)); err != nil {
+			return fmt.Errorf("deftype: %w", err)
+		}
+	}
+	oldType = in.UnaliasType(oldType)
+	if _, ok := in.types[oldType.Canonical()]; !ok {
+		return fmt.Errorf("Basic type does not exist: %v", oldType)
+	}
+	// type arguments are stored as canonical type variables :a, :b ...
+	vars := map[string]Type{}
+	for i, a := range newType.Arguments() {
+		vars[string(a)] = Type(rune('a' + i))
+	}
+	in.types[newType.Canonical()] = oldType.Expand(vars)
+	return nil
+}
+
+// (contract :name (fn1 :type1 :type2) :return (fn2 ...) :return ...)
+// defines contract :name which can be used as type variable.
+// Types bound to the contract should provide required functions fn1, fn2 ...
+// (contract (:a :b) ...) defines several contracts with the same requirements.
+func (in *Interpret) defineContract(args []Param) error {
+	if len(args) < 1 {
+		return fmt.Errorf("Not enougn arguments to contract: %v", args)
+	}
+	var names []Param
+	switch cs := args[0].V.(type) {
+	case Ident:
+		names = args[:1]
+	case *Sexpr:
+		names = cs.List
+	default:
+		return fmt.Errorf("Contract expect first argument to be type, found: %v", args[0])
+	}
+	var contracts []Type
+	for _, n := range names {
+		id, _ := n.V.(Ident)
+		t, ok := ParseType(string(id))
+		if !ok || len(t.Arguments()) > 0 {
+			return fmt.Errorf("Contract expect first argument to be type, found: %v", n)
+		}
+		if _, ok := in.types[t]; ok {
+			if in.IsContract(t) && len(args) == 1 && len(in.contractFuncs[t]) == 0 {
+				// the same type variable may be declared by several modules
+				continue
+			}
+			return fmt.Errorf("Cannot define contract %v: type already exist", t)
+		}
+		contracts = append(contracts, t)
+	}
+	for _, t := range contracts {
+		in.types[t] = ""
+		in.contracts[t] = struct{}{}
+	}
+	funcs, err := in.parseContractFuncs(args[1:])
+	if err != nil {
+		for _, t := range contracts {
+			delete(in.types, t)
+			delete(in.contracts, t)
+		}
+		return fmt.Errorf("Cannot define contract %v: %w", args[0], err)
+	}
+	for _, t := range contracts {
+		in.contractFuncs[t] = funcs
+	}
+	return nil
+}
+
+func (in *Interpret) canConvertType(from, to Type) (bool, error) {
+	f, t := in.unaliasExpr(in.typeExpr(from)), in.unaliasExpr(in.typeExpr(to))
+	if f.Name == "func" && t.Name == "func" {
+		// types of arguments and return values should match
+		return in.matchTypeExpr(t, f, &map[string]Type{})
+	}
+	if from == TypeUnknown || to == TypeUnknown || from == TypeNothing {
+		return true, nil
+	}
+
+	target := t.Canonical().Type()
+	if _, ok := in.types[target]; !ok {
+		return false, fmt.Errorf("Cannot convert type %v into %v: %v is not defined", from, to, to)
+	}
+	for f != nil {
+		f = in.unaliasExpr(f)
+		if f.Canonical().Type() == target {
+			return true, nil
+		}
+		parent, err := in.parentExpr(f)
+		if err != nil {
+			return false, fmt.Errorf("Cannot convert type %v into %v: %w", from, to, err)
+		}
+		f = parent
+	}
+	return false, nil
+}
+
+// covariantType returns true if types differ only in type arguments
+// and value of type from can be used as value of type to (e.g. :list[int] as :list[any]).
+func (in *Interpret) covariantType(from, to Type) bool {
+	from, to = in.UnaliasType(from), in.UnaliasType(to)
+	if from.Basic() != to.Basic() || len(from.Arguments()) == 0 {
+		return false
+	}
+	ok, _ := in.matchType(to, from, &map[string]Type{})
+	return ok
+}
+
+func (in *Interpret) FPrint(args []Param) (*Param, error) {
+	for i, e := range args {
+		if i > 0 {
+			fmt.Fprintf(in.output, " ")
+		}
+		e.V.Print(in.output)
+	}
+	fmt.Fprintf(in.output, "\n")
+	return &Param{V: QEmpty, T: TypeList}, nil
+}
+
+func (in *Interpret) fakeFunc(args []Expr) (Expr, error) {
+	panic("The fakeFunc should not be called")
+}
+
+// convert string or float into int
+func (in *Interpret) FInt(args []Param) (*Param, error) {
+	if len(args) != 1 {
+		return nil, fmt.Errorf("FInt: expected exaclty one argument, found %v", args)
+	}
+	switch a := args[0].V.(type) {
+	case Int:
+		return &Param{V: a, T: TypeInt}, nil
+	case Float:
+		if f := float64(a); math.IsNaN(f) || math.IsInf(f, 0) {
+			return nil, fmt.Errorf("FInt: cannot convert argument into Int: %v", a)
+		}
+		// truncate towards zero
+		i, ok := in.intMaker.ParseInt(new(big.Float).SetFloat64(math.Trunc(float64(a))).Text('f', 0))
+		if !ok {
+			return nil, fmt.Errorf("FInt: cannot convert argument into Int: %v", a)
+		}
+		return &Param{V: i, T: TypeInt}, nil
+	case *Rational:
+		// truncate towards zero
+		i, ok := in.intMaker.ParseInt(new(big.Int).Quo(a.value.Num(), a.value.Denom()).String())
+		if !ok {
+			return nil, fmt.Errorf("FInt: cannot convert argument into Int: %v", a)
+		}
+		return &Param{V: i, T: TypeInt}, nil
+	}
+	s, ok := args[0].V.(Str)
+	if !ok {
+		return nil, fmt.Errorf("FInt: expected argument to be Str, found %v", args)
+	}
+	i, ok := in.intMaker.ParseInt(string(s))
+	if !ok {
+		return nil, fmt.Errorf("FInt: cannot convert argument into Int: %v", s)
+	}
+	return &Param{V: i, T: TypeInt}, nil
+}
+
+func (in *Interpret) FLength(args []Param) (*Param, error) {
+	if len(args) != 1 {
+		return nil, fmt.Errorf("FLength: expected exaclty one argument, found %v", args)
+	}
+
+	if a, ok := args[0].V.(Lenghter); ok {
+		return &Param{V: in.intMaker.MakeInt(int64(a.Length())), T: TypeInt}, nil
+	}
+
+	a, ok := args[0].V.(List)
+	if !ok {
+		return nil, fmt.Errorf("FLength: expected argument to be List, found %v", args[0])
+	}
+	var l int64
+	for !a.Empty() {
+		l++
+		var err error
+		a, err = a.Tail()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &Param{V: in.intMaker.MakeInt(l), T: TypeInt}, nil
+}
+
+func (in *Interpret) FNth(args []Param) (*Param, error) {
+	if len(args) != 2 {
+		return nil, fmt.Errorf("FNth: expected exaclty two arguments, found %v", args)
+	}
+	bign, ok := args[0].V.(Int)
+	if !ok {
+		return nil, fmt.Errorf("FNth: expected first argument to be Int, fount: %v", args[0])
+	}
+	n := int(bign.Int64())
+	if nther, ok := args[1].V.(Nther); ok {
+		return nther.Nth(n)
+	}
+	a, ok := args[1].V.(List)
+	if !ok {
+		return nil, fmt.Errorf("FNth: expected second argument to be List, found %v", args[1])
+	}
+	if n <= 0 {
+		return nil, fmt.Errorf("FNth: n should be >= 1, found: %v", n)
+	}
+	// numeration starts with 1
+	for n > 1 {
+		var err error
+		a, err = a.Tail()
+		if err != nil {
+			return nil, err
+		}
+		n--
+	}
+	return a.Head()
+}
+
+func (in *Interpret) NewLambdaName() (name string) {
+	name = fmt.Sprintf("__lambda__%03d", in.lambdaCount)
+	in.lambdaCount++
+	return
+}
+
+func (in *Interpret) Stat() {
+	fmt.Fprintf(os.Stderr, "Functions:\n")
+	for fname, _ := range in.funcs {
+		fmt.Fprintf(os.Stderr, "%v\n", fname)
+	}
+}
+
+func (i *Interpret) CheckReturnTypes() (errs []error) {
+	_, err := i.evalBodyType("__main__", i.mainBody, mainVars(), nil)
+	if err != nil {
+		errs = append(errs, err)
+	}
+	for _, fn := range i.funcs {
+		fi, ok := fn.(*FuncInterpret)
+		if !ok {
+			// native function
+			continue
+		}
+
+		for _, impl := range fi.bodies {
+			errs = append(errs, i.checkImpl(fi, impl)...)
+		}
+	}
+	return
+}
+
+// types of variables available in the main body
+func mainVars() map[string]Type {
+	vars := map[string]Type{
+		"__stdin": TypeStr,
+		"__args":  Type("list[str]"),
+	}
+	for i := 1; i <= 9; i++ {
+		vars[fmt.Sprintf("_%d", i)] = TypeStr
+	}
+	return vars
+}
+
+func (i *Interpret) checkImpl(fi *FuncInterpret, impl *FuncImpl) (errs []error) {
+	if i.strictTypes {
+		if fi.returnType == TypeUnknown {
+			err := fmt.Errorf("%v: return type should be specified in strict mode", fi.name)
+			errs = append(errs, withPos(impl.pos, err))
+		}
+		if impl.argfmt.Wildcard == "" {
+			for _, a := range impl.argfmt.Args {
+				if a.T == TypeUnknown {
+					err := fmt.Errorf("%v: arument type should be specified in strict mode: %v", fi.name, a.Name)
+					errs = append(errs, withPos(impl.pos, err))
+				}
+			}
+		}
+	}
+	if impl.guard != nil {
+		gt, err := i.exprType(fi.name, *impl.guard, impl.argfmt.Values())
+		if err != nil {
+			errs = append(errs, err)
+		} else if gt != TypeBool && gt != TypeUnknown {
+			err := fmt.Errorf("%v: guard should be :bool, found: %v", fi.name, gt)
+			errs = append(errs, withPos(impl.guard.Pos, err))
+		}
+	}
+	t, err := i.evalBodyType(fi.name, impl.body, impl.argfmt.Values(), nil)
+	if err != nil {
+		// return type is unknown if body is incorrect
+		return append(errs, err)
+	}
+	if fi.returnType != TypeAny && fi.returnType != TypeUnknown && !i.IsGeneric(fi.returnType) {
+		if t != fi.returnType && t != TypeNothing && !i.covariantType(t, fi.returnType) {
+			err := fmt.Errorf("Incorrect return value in function %v %v: expected %v actual %v", fi.name, impl.argfmt, fi.returnType, t)
+			errs = append(errs, withPos(impl.pos, err))
+		}
+	}
+	return
+}
+
+func (in *Interpret) evalBodyType(fname string, body []Param, vars map[string]Type, types map[string]Type) (rt Type, err error) {
+	if len(body) == 0 {
+		// This should be possible only for __main__ function
+		return TypeAny, err
+	}
+
+	u := TypeUnknown
+	for _, stt := range body[:len(body)-1] {
+		if err := in.statementType(fname, stt, vars); err != nil {
+			return u, err
+		}
+	}
+	rt, err = in.exprType(fname, body[len(body)-1], vars)
+	if err != nil {
+		return u, err
+	}
+	rt = in.UnaliasType(rt)
+	return rt.Expand(types), nil
+}
+
+// statementType checks type of non-last statement of the function body.
+// Types of variables defined with 'set' are stored into vars.
+func (in *Interpret) statementType(fname string, stt Param, vars map[string]Type) error {
+	a, ok := stt.V.(*Sexpr)
+	if !ok || a.Quoted || a.Empty() {
+		return nil
+	}
+	ident, ok := a.List[0].V.(Ident)
+	if _, computed := a.List[0].V.(*Sexpr); !ok && !computed {
+		return withPos(stt.Pos, fmt.Errorf("Expected ident, found: %v", a.List[0]))
+	}
+	// call of function returned by expression is checked by exprType
+	switch name := string(ident); name {
+	case "set", "set'":
+		varname, ok := a.List[1].V.(Ident)
+		if !ok {
+			return withPos(stt.Pos, fmt.Errorf("%v: second argument should be variable name, found: %v", name, a.List[1]))
+		}
+		if len(a.List) == 4 {
+			id, ok := a.List[3].V.(Ident)
+			if !ok {
+				return withPos(stt.Pos, fmt.Errorf("Fourth statement of %v should be type identifier, found: %v", name, a.List[3]))
+			}
+			tp, err := in.parseType(string(id))
+			if err != nil {
+				return withPos(stt.Pos, fmt.Errorf("Fourth statement of %v should be type identifier, found: %v (%v)", name, a.List[3], err))
+			}
+			vars[string(varname)] = tp.Expand(in.typeBinds)
+		} else if len(a.List) == 3 {
+			tp, err := in.exprType(fname, a.List[2], vars)
+			if err != nil {
+				return err
+			}
+			vars[string(varname)] = tp
+		} else {
+			return withPos(stt.Pos, fmt.Errorf("%v: incorrect number of arguments %v: %v", fname, name, a.List))
+		}
+	case "print":
+		for i, arg := range a.List[1:] {
+			_, err := in.exprType(fname, arg, vars)
+			if err != nil {
+				return prefixErr(fmt.Sprintf("%v: incorrect argument to print at posision %v", fname, i), err)
+			}
+		}
+	default:
+		if _, err := in.exprType(fname, stt, vars); err != nil {
+			return prefixErr(fname, err)
+		}
+	}
+	return nil
+}
+
+// letType checks (let ((var value [:type]) ...) body...) expression.
+// Variables bound by let are visible only in its body.
+func (in *Interpret) letType(fname string, a *Sexpr, vars map[string]Type) (Type, error) {
+	const u = TypeUnknown
+	form := a.List[0].V.(Ident)
+	if len(a.List) < 3 {
+		return u, fmt.Errorf("%v: %v expects list of bindings and body, found: %v", fname, form, a)
+	}
+	bindings, ok := a.List[1].V.(*Sexpr)
+	if !ok || bindings.Quoted {
+		return u, fmt.Errorf("%v: %v expects list of bindings, found: %v", fname, form, a.List[1])
+	}
+	scope := make(map[string]Type, len(vars)+len(bindings.List))
+	for k, v := range vars {
+		scope[k] = v
+	}
+	// values of 'let' do not see variables bound by the same let
+	outer := vars
+	if form == "let*" {
+		outer = scope
+	}
+	bound := map[string]Type{}
+	for _, b := range bindings.List {
+		bse, ok := b.V.(*Sexpr)
+		if !ok || (len(bse.List) != 2 && len(bse.List) != 3) {
+			return u, withPos(b.Pos, fmt.Errorf("%v: %v expects binding (name value [:type]), found: %v", fname, form, b))
+		}
+		name, ok := bse.List[0].V.(Ident)
+		if !ok {
+			return u, withPos(b.Pos, fmt.Errorf("%v: %v expects variable name, found: %v", fname, form, bse.List[0]))
+		}
+		if _, dup := bound[string(name)]; dup && form == "let" {
+			return u, withPos(b.Pos, fmt.Errorf("%v: duplicate binding %v in let", fname, string(name)))
+		}
+		t, err := in.exprType(fname, bse.List[1], outer)
+		if err != nil {
+			return u, err
+		}
+		if len(bse.List) == 3 {
+			id, ok := bse.List[2].V.(Ident)
+			if !ok {
+				return u, withPos(b.Pos, fmt.Errorf("%v: %v expects type identifier, found: %v", fname, form, bse.List[2]))
+			}
+			if t, err = in.parseType(string(id)); err != nil {
+				return u, withPos(b.Pos, err)
+			}
+			t = t.Expand(in.typeBinds)
+		}
+		bound[string(name)] = t
+		if form == "let*" {
+			scope[string(name)] = t
+		}
+	}
+	return in.scopeType(fname, a.List[2:], vars, bound)
+}
+
+// scopeType checks body in which variables bound are visible.
+func (in *Interpret) scopeType(fname string, body []Param, vars map[string]Type, bound map[string]Type) (Type, error) {
+	scope := make(map[string]Type, len(vars)+len(bound))
+	for name, t := range vars {
+		scope[name] = t
+	}
+	for name, t := range bound {
+		scope[name] = t
+	}
+	rt, err := in.evalBodyType(fname, body, scope, nil)
+	if err != nil {
+		return TypeUnknown, err
+	}
+	// variables defined with 'set' inside of the body are visible after it
+	for name, t := range scope {
+		if _, ok := bound[name]; !ok {
+			vars[name] = t
+		}
+	}
+	return rt, nil
+}
+
+var reArg = regexp.MustCompile(`^_[0-9]+$`)
+
+func (i *Interpret) exprType(fname string, e Param, vars map[string]Type) (result Type, err error) {
+	const u = TypeUnknown
+	defer func() {
+		err = withPos(e.Pos, err)
+	}()
+	switch a := e.V.(type) {
+	case Int, Float, *Rational:
+		return e.T, nil
+	case Str:
+		return e.T, nil
+	case Bool:
+		return e.T, nil
+	case Ident:
+		if t, ok := vars[string(a)]; ok {
+			return t, nil
+		} else if fe, ok := i.funcs[string(a)]; ok {
+			if fu, ok := fe.(*FuncInterpret); ok {
+				return fu.FuncType(), nil
+			}
+			return TypeFunc, nil
+		} else if t, err := i.parseType(string(a)); err == nil {
+			return t.Expand(i.typeBinds), nil
+		}
+		if string(a) == "__args" || reArg.MatchString(string(a)) {
+			return TypeAny, nil
+		}
+		return u, fmt.Errorf("Undefined variable: %v", string(a))
+	case *Sexpr:
+		if a.Quoted || a.Empty() {
+			return i.literalType(a), nil
+		}
+		if a.Lambda {
+			return i.shortLambdaType(fname, []Param{{V: &Sexpr{List: a.List}, T: e.T, Pos: e.Pos}}, vars)
+		}
+		ident, ok := a.List[0].V.(Ident)
+		if !ok {
+			if _, ok := a.List[0].V.(*Sexpr); !ok {
+				return u, fmt.Errorf("%v: expected ident, found: %v", fname, a.List[0])
+			}
+			// call of function returned by expression, e.g. ((adder 1) 2)
+			ft, err := i.exprType(fname, a.List[0], vars)
+			if err != nil {
+				return u, err
+			}
+			return i.funcValueType(fname, a.List[0].String(), ft, a, vars)
+		}
+		switch name := string(ident); name {
+		case "set", "set'":
+			return u, fmt.Errorf("%v: unexpected %v and the end of function", fname, ident)
+		case "lambda":
+			return i.lambdaType(fname, &Sexpr{List: a.List[1:]}, vars)
+		case "and", "or":
+			return TypeBool, nil
+		case "gen", "gen'":
+			if len(a.List) < 3 {
+				return u, fmt.Errorf("%v: %v expects function and initial state, found: %v", fname, name, a.List[1:])
+			}
+			ft, err := i.exprType(fname, a.List[1], vars)
+			if err != nil {
+				return u, err
+			}
+			if args := ft.Arguments(); ft.Basic() == "func" && len(args) > 0 {
+				return i.genType(args[len(args)-1]), nil
+			}
+			return TypeList, nil
+		case "apply":
+			if len(a.List) != 3 {
+				return u, fmt.Errorf("%v: incorrect number of arguments to 'apply': %v", fname, a.List)
+			}
+			ftype, err := i.exprType(fname, a.List[1], vars)
+			if err != nil {
+				return u, err
+			}
+			if ftype.Basic() != "func" {
+				return u, fmt.Errorf("%v: apply expects function on first place, found: %v", fname, a.List[1])
+			}
+			atype, err := i.exprType(fname, a.List[2], vars)
+			if err != nil {
+				return u, err
+			}
+			if atype.Basic() != "list" && atype != TypeUnknown {
+				return u, fmt.Errorf("%v: apply expects list on second place, found: %v (%v)", fname, a.List[2], atype)
+			}
+			id, ok := a.List[1].V.(Ident)
+			if _, isVar := vars[string(id)]; !ok || isVar {
+				// function value (variable or lambda)
+				if args := ftype.Arguments(); len(args) > 0 {
+					return args[len(args)-1], nil
+				}
+				return u, nil
+			}
+			fi, ok := i.funcs[string(id)]
+			if !ok {
+				return u, fmt.Errorf("%v: unknown function supplied to apply: %v", fname, a.List[1])
+			}
+			return fi.ReturnType(), nil
+		case "if":
+			if len(a.List) != 4 {
+				return u, fmt.Errorf("%v: incorrect number of arguments to 'if': %v", fname, a.List)
+			}
+			condType, err := i.exprType(fname, a.List[1], vars)
+			if err != nil {
+				return u, err
+			}
+			if condType != TypeBool && condType != TypeUnknown {
+				return u, fmt.Errorf("%v: condition in if-statement should return :bool, found: %v", fname, condType)
+			}
+			t1, err := i.exprType(fname, a.List[2], vars)
+			if err != nil {
+				return u, err
+			}
+			t2, err := i.exprType(fname, a.List[3], vars)
+			if err != nil {
+				return u, err
+			}
+			return i.joinTypes(t1, t2), nil
+		case "try":
+			// (try expr (catch err handler...))
+			if len(a.List) != 3 {
+				return u, fmt.Errorf("%v: incorrect number of arguments to 'try': %v", fname, a.List)
+			}
+			catch, ok := a.List[2].V.(*Sexpr)
+			if !ok || len(catch.List) < 3 || catch.List[0].V != Ident("catch") {
+				return u, fmt.Errorf("%v: try expects (catch <var> <expr>...) as second argument, found: %v", fname, a.List[2])
+			}
+			errVar, ok := catch.List[1].V.(Ident)
+			if !ok {
+				return u, fmt.Errorf("%v: catch expects variable name, found: %v", fname, catch.List[1])
+			}
+			t1, err := i.exprType(fname, a.List[1], vars)
+			if err != nil {
+				return u, err
+			}
+			// error variable is visible only in the catch-block
+			t2, err := i.scopeType(fname, catch.List[2:], vars, map[string]Type{string(errVar): TypeError})
+			if err != nil {
+				return u, err
+			}
+			return i.joinTypes(t1, t2), nil
+		case "do":
+
+			res, err := i.evalBodyType(fname, a.List[1:], vars, nil)
+			return res, err
+		case "quasiquote":
+			if len(a.List) != 2 {
+				return u, fmt.Errorf("%v: incorrect number of arguments to 'quasiquote': %v", fname, a.List)
+			}
+			if err := i.quasiquoteType(fname, a.List[1], vars); err != nil {
+				return u, err
+			}
+			return TypeList, nil
+		case "unquote", "unquote-splicing":
+			return u, fmt.Errorf("%v: %v outside of quasiquote", fname, name)
+		case "let", "let*":
+			return i.letType(fname, a, vars)
+		case "cond":
+			return i.condType(fname, a, vars)
+		case "case":
+			return i.caseType(fname, a, vars)
+		default:
+			// this is a function call
+			if tvar, ok := vars[name]; ok {
+				return i.funcValueType(fname, name, tvar, a, vars)
+			}
+			f, ok := i.funcs[name]
+			if !ok {
+				return u, withPos(e.Pos, fmt.Errorf("%v: unknown function %v", fname, name))
+			}
+
+			// check if we have matching func impl
+			params := []Param{}
+			for _, item := range a.List[1:] {
+				switch a := item.V.(type) {
+				case Int, Float, Str, Bool:
+					params = append(params, item)
+				case *Sexpr:
+					if a.Empty() || a.Quoted {
+						params = append(params, Param{T: i.literalType(a), V: a})
+					} else if a.Lambda {
+						params = append(params, Param{T: TypeFunc})
+					} else {
+						itemType, err := i.exprType(fname, item, vars)
+						if err != nil {
+							return u, err
+						}
+						if i.IsGeneric(itemType) {
+							itemType = TypeUnknown
+						}
+						params = append(params, Param{T: itemType})
+					}
+				case Ident:
+					itemType, err := i.exprType(fname, item, vars)
+					if err != nil {
+						return u, err
+					}
+					if i.IsGeneric(itemType) {
+						itemType = TypeUnknown
+					}
+					params = append(params, Param{T: itemType})
+				default:
+					panic(fmt.Errorf("%v: unexpected type: %v", fname, item))
+				}
+			}
+			idx, t, _, err := f.TryBind(params)
+			if err != nil {
+				return u, prefixErr(fname, err)
+			}
+			if fi, ok := f.(*FuncInterpret); ok && t == TypeUnknown && idx >= 0 {
+				if t, err = i.inferReturnType(fi, idx, params); err != nil {
+					return u, prefixErr(fname, err)
+				}
+			}
+
+			return t, nil
+		}
+	}
+	fmt.Fprintf(os.Stderr, "Unexpected return. (TypeAny)\n")
+	return TypeAny, nil
+}
+
+// joinTypes returns type of expression which evaluates into one of two branches.
+func (in *Interpret) joinTypes(t1, t2 Type) Type {
+	if t1 == TypeNothing {
+		return t2
+	}
+	if t2 == TypeNothing {
+		return t1
+	}
+	if t1 == TypeUnknown || t2 == TypeUnknown {
+		return TypeUnknown
+	}
+	t1 = in.UnaliasType(t1)
+	t2 = in.UnaliasType(t2)
+	if t1 == t2 {
+		return t1
+	}
+	// e.g. :list[nothing] and :list[int] are joined into :list[int]
+	if e1, e2 := in.typeExpr(t1), in.typeExpr(t2); e1.Name == e2.Name && len(e1.Args) > 0 && len(e1.Args) == len(e2.Args) {
+		res := &TypeExpr{Name: e1.Name}
+		for i := range e1.Args {
+			res.Args = append(res.Args, in.typeExpr(in.joinTypes(e1.Args[i].Type(), e2.Args[i].Type())))
+		}
+		return res.Type()
+	}
+	// e.g. :int and :rational are joined into :rational
+	if ok, err := in.canConvertType(t1, t2); err == nil && ok {
+		return t2
+	}
+	if ok, err := in.canConvertType(t2, t1); err == nil && ok {
+		return t1
+	}
+	return TypeAny
+}
+
+func (in *Interpret) UnaliasType(t Type) Type {
+	if tt, ok := in.typeAliases[t]; ok {
+		return tt
+	}
+	return t
+}
+
+func (in *Interpret) parseType(token string) (Type, error) {
+	t, ok := ParseType(token)
+	if !ok {
+		return TypeUnknown, fmt.Errorf("Token is not a type: %q", token)
+	}
+	te, err := ParseTypeExpr(string(t))
+	if err != nil {
+		return "", err
+	}
+	if u := in.undefinedType(te); u == te {
+		return "", fmt.Errorf("Cannot parse type %v: not defined", token)
+	} else if u != nil {
+		return "", fmt.Errorf("Cannot parse type %v: %v is not defined", token, u)
+	}
+	return in.UnaliasType(t), nil
+}
+
+// undefinedType returns the type or its argument which is not defined (nil if all of them are defined).
+func (in *Interpret) undefinedType(te *TypeExpr) *TypeExpr {
+	// functions may have any number of arguments
+	if t := in.unaliasExpr(te); t.Name != "func" || len(t.Args) == 0 {
+		if _, ok := in.types[t.Canonical().Type()]; !ok {
+			return te
+		}
+	}
+	for _, a := range te.Args {
+		if u := in.undefinedType(a); u != nil {
+			return u
+		}
+	}
+	return nil
+}
+
+// toParent converts type into its parent type with the given basic type,
+// e.g. :str into :list[str] or :vector[int] into :list[int].
+func (in *Interpret) toParent(from, parent Type) (Type, error) {
+	te, err := in.toParentExpr(in.typeExpr(from), parent.Basic())
+	if err != nil {
+		return TypeUnknown, err
+	}
+	return te.Type(), nil
+}
+
+func (in *Interpret) toParentExpr(from *TypeExpr, name string) (*TypeExpr, error) {
+	f := from
+	for f != nil {
+		if f.Name == name {
+			return f, nil
+		}
+		f = in.unaliasExpr(f)
+		if f.Name == name {
+			return f, nil
+		}
+		parent, err := in.parentExpr(f)
+		if err != nil {
+			return nil, fmt.Errorf("Cannot convert type %v into :%v: %w", from, name, err)
+		}
+		f = parent
+	}
+	return nil, fmt.Errorf("Cannot convert %v into :%v", from, name)
+}
+
+func (in *Interpret) IsContract(t Type) bool {
+	_, ok := in.contracts[t]
+	return ok
+}
+
+// addFreeVars binds type variables of t which are not bound yet to :any.
+func (in *Interpret) addFreeVars(t Type, types map[string]Type) {
+	in.addFreeVarsExpr(in.typeExpr(t), types)
+}
+
+func (in *Interpret) addFreeVarsExpr(te *TypeExpr, types map[string]Type) {
+	if in.isTypeVar(te) {
+		if _, ok := types[te.Name]; !ok {
+			types[te.Name] = TypeAny
+		}
+		return
+	}
+	for _, a := range te.Args {
+		in.addFreeVarsExpr(a, types)
+	}
+}
+
+func (in *Interpret) IsGeneric(t Type) bool {
+	if in.IsContract(t) {
+		return true
+	}
+	if !strings.Contains(string(t), "[") {
+		return false
+	}
+	return in.isGenericExpr(in.typeExpr(t))
+}
+
+func (in *Interpret) isGenericExpr(te *TypeExpr) bool {
+	if in.isTypeVar(te) {
+		return true
+	}
+	for _, a := range te.Args {
+		if in.isGenericExpr(a) {
+			return true
+		}
+	}
+	return false
+}
+
+// funcValueType returns type of call of function value (variable or expression) of type tvar.
+// Arguments of the call are checked against argument types of the function.
+func (i *Interpret) funcValueType(fname string, name string, tvar Type, a *Sexpr, vars map[string]Type) (Type, error) {
+	u := TypeUnknown
+	if tvar == TypeFunc || tvar == TypeUnknown {
+		return TypeUnknown, nil
+	}
+	if tvar.Basic() == "func" {
+		args := tvar.Arguments()
+		if len(args) == 0 {
+			return u, fmt.Errorf("%v: incorrect function type of %v: %v", fname, name, tvar)
+		}
+		rt := args[len(args)-1]
+		for idx, item := range a.List[1:] {
+			switch a := item.V.(type) {
+			case Int:
+				if ok, err := i.canConvertType(TypeInt, args[idx]); !ok || err != nil {
+					return u, fmt.Errorf("%v: cannot use %v as argument %d to %v: expected %v, found %v", fname, item, idx, name, args[idx], TypeInt)
+				}
+			case Float:
+				if ok, err := i.canConvertType(TypeFloat, args[idx]); !ok || err != nil {
+					return u, fmt.Errorf("%v: cannot use %v as argument %d to %v: expected %v, found %v", fname, item, idx, name, args[idx], TypeFloat)
+				}
+			case Str:
+				if ok, err := i.canConvertType(TypeStr, args[idx]); !ok || err != nil {
+					return u, fmt.Errorf("%v: cannot use %v as argument %d to %v: expected %v, found %v", fname, item, idx, name, args[idx], TypeStr)
+				}
+			case Bool:
+				if ok, err := i.canConvertType(TypeBool, args[idx]); !ok || err != nil {
+					return u, fmt.Errorf("%v: cannot use %v as argument %d to %v: expected %v, found %v", fname, item, idx, name, args[idx], TypeBool)
+				}
+			case *Sexpr:
+				if a.Empty() || a.Quoted {
+					if ok, err := i.canConvertType(i.literalType(a), args[idx]); !ok || err != nil {
+						return u, fmt.Errorf("%v: cannot use %v as argument %d to %v: expected %v, found %v", fname, item, idx, name, args[idx], i.literalType(a))
+					}
+				} else if a.Lambda {
+					if ok, err := i.canConvertType(TypeFunc, args[idx]); !ok || err != nil {
+						return u, fmt.Errorf("%v: cannot use %v as argument %d to %v: expected %v, found %v", fname, item, idx, name, args[idx], TypeFunc)
+					}
+				} else {
+					itemType, err := i.exprType(fname, item, vars)
+					if err != nil {
+						return u, err
+					}
+					if !i.IsGeneric(itemType) {
+						if ok, err := i.canConvertType(itemType, args[idx]); !ok || err != nil {
+							return u, fmt.Errorf("%v: cannot use %v as argument %d to %v: expected %v, found %v", fname, item, idx, name, args[idx], itemType)
+						}
+					}
+				}
+			case Ident:
+				itemType, err := i.exprType(fname, item, vars)
+				if err != nil {
+					return u, err
+				}
+				if !i.IsGeneric(itemType) {
+					if ok, err := i.canConvertType(itemType, args[idx]); !ok || err != nil {
+						return u, fmt.Errorf("%v: cannot use %v as argument %d to %v: expected %v, found %v", fname, item, idx, name, args[idx], itemType)
+					}
+				}
+			default:
+				panic(fmt.Errorf("%v: unexpected type: %v", fname, item))
+			}
+		}
+		return rt, nil
+	}
+	return u, fmt.Errorf("%v: expected '%v' to be function, found: %v", fname, name, tvar)
+}
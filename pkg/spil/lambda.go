@@ -0,0 +1,93 @@
+package spil
+
+import (
+	"fmt"
+	"io"
+)
+
+// Lambda is a function value created by lambda expression.
+// Values of variables of the enclosing scope which are used in its body
+// are captured when lambda is created.
+type Lambda struct {
+	fi *FuncInterpret
+}
+
+var _ Expr = (*Lambda)(nil)
+
+func (l *Lambda) String() string {
+	return fmt.Sprintf("{Lambda: %v}", l.fi.name)
+}
+
+// Lambdas are compared by identity.
+func (l *Lambda) Hash() (string, error) {
+	return fmt.Sprintf("{Lambda: %p}", l), nil
+}
+
+func (l *Lambda) Print(w io.Writer) {
+	io.WriteString(w, "<"+l.fi.name+">")
+}
+
+func (l *Lambda) Type() Type {
+	return l.fi.FuncType()
+}
+
+// lambdaSignature returns list of arguments and return type of typed lambda:
+// (lambda (x:int y:int) :int body...)
+func lambdaSignature(se *Sexpr) (args *Sexpr, rt Type, ok bool) {
+	if len(se.List) < 3 {
+		return nil, "", false
+	}
+	args, ok = se.List[0].V.(*Sexpr)
+	if !ok || args.Quoted || args.Lambda {
+		return nil, "", false
+	}
+	for _, arg := range args.List {
+		if _, ok := arg.V.(Ident); !ok {
+			return nil, "", false
+		}
+	}
+	id, ok := se.List[1].V.(Ident)
+	if !ok {
+		return nil, "", false
+	}
+	rt, ok = ParseType(string(id))
+	return args, rt, ok
+}
+
+// Typers
+
+// lambdaType checks body of typed lambda and returns its function type.
+// Types of lambdas with implicit arguments _1, _2 ... are inferred by shortLambdaType.
+func (in *Interpret) lambdaType(fname string, se *Sexpr, vars map[string]Type) (Type, error) {
+	args, rt, ok := lambdaSignature(se)
+	if !ok {
+		return in.shortLambdaType(fname, se.List, vars)
+	}
+	rt, err := in.parseType(rt.String())
+	if err != nil {
+		return TypeUnknown, err
+	}
+	af, err := ParseArgFmt(args)
+	if err != nil {
+		return TypeUnknown, err
+	}
+	if err := in.resolvePatterns(af.Args); err != nil {
+		return TypeUnknown, err
+	}
+	scope := af.Values()
+	for name, t := range vars {
+		if _, ok := scope[name]; !ok {
+			scope[name] = t
+		}
+	}
+	t, err := in.evalBodyType(fname, se.List[2:], scope, nil)
+	if err != nil {
+		return TypeUnknown, err
+	}
+	if rt != TypeAny && !in.IsGeneric(rt) && t != rt && t != TypeNothing {
+		if ok, _ := in.canConvertType(t, rt); !ok {
+			return TypeUnknown, fmt.Errorf("%v: incorrect return value of lambda %v: expected %v, actual %v", fname, af, rt, t)
+		}
+	}
+	return makeFuncType(af, rt), nil
+}
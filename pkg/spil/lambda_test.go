@@ -0,0 +1,45 @@
+package spil
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLambdasAreNotRegistered(t *testing.T) {
+	input := `(def loop (0 acc:int) :int acc)
+(def loop (n:int acc:int) :int
+	(set add (lambda (+ _1 n)))
+	(loop (- n 1) (add acc)))
+(print (loop 1000 0))`
+	out := &strings.Builder{}
+	in := NewInterpreter(out, getTestLibraryDir())
+	if err := in.Parse("test.lisp", strings.NewReader(input)); err != nil {
+		t.Fatal(err)
+	}
+	funcs := len(in.funcs)
+	if err := in.Run(nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	if act, exp := out.String(), "500500\n"; act != exp {
+		t.Errorf("Incorrect output: expected %q, actual %q", exp, act)
+	}
+	if len(in.funcs) != funcs {
+		t.Errorf("Lambdas should not be registered as functions: %v functions before run, %v after", funcs, len(in.funcs))
+	}
+}
+
+func TestTypedLambdas(t *testing.T) {
+	tests := []programTest{
+		{"type", "(print (type (lambda (x:int y:int) :int (+ x y))))", ":func[int,int,int]\n", ""},
+		{"return type", "(set f (lambda (x:int) :str x))\n(print 1)", "", "incorrect return value of lambda (x:int): expected :str, actual :int"},
+		{"argument types", "(set f (lambda (x:int) :int x))\n(print (f \"a\"))", "", "as argument 0 to f: expected :int, found :str"},
+		{"captured", "(def f (n:int) :func[int,int] (lambda (x:int) :int (* x n)))\n(print ((f 3) 2))", "6\n", ""},
+		{"call of expression", "(def f (n:int) :func[int,int] (lambda (x:int) :int (* x n)))\n(print ((f 3) \"a\"))", "", "expected :int, found :str"},
+		{"lambda call statement", "(def f (n:int) :int ((lambda (x:int) :int (do (print x) x)) n) 1)\n((lambda (x:int) :int (do (print x) x)) 3)\n(print (f 2))", "3\n2\n1\n", ""},
+		{"returned function statement", "(def mk () :func[int,int] (lambda (x:int) :int (do (print x) x)))\n(def f (n:int) :int ((mk) n) n)\n(print (f 5))", "5\n5\n", ""},
+		{"apply lambda", "(print (type (apply (lambda (x:int) :int (+ x 1)) '(4))) (apply \\(+ _1 _2) '(4 5)))", ":int 9\n", ""},
+		{"closure", "(def f (n:int) :func[int,int] (lambda (x:int) :int (* x n)))\n(set g (f 3))\n(print (g 2))", "6\n", ""},
+		{"short lambda", "(print (type \\(+ _1 1)))", ":func\n", ""},
+	}
+	checkPrograms(t, tests)
+}
@@ -1,4 +1,4 @@
-package main
+package spil
 
 import (
 	"fmt"
@@ -93,7 +93,7 @@ func (l *LazyList) Head() (*Param, error) {
 func (l *LazyList) next() (err error) {
 	expr, err := l.iter.Eval(l.state)
 	if err != nil {
-		return fmt.Errorf("LazyList: Eval(%v) failed: %v", l.state, err)
+		return prefixErr(fmt.Sprintf("LazyList: Eval(%v) failed", l.state), err)
 	}
 	res, ok := expr.V.(*Sexpr)
 	if !ok {
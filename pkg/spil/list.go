@@ -0,0 +1,108 @@
+package spil
+
+import (
+	"fmt"
+)
+
+// Types of lists: element types are preserved when values are placed into lists,
+// e.g. '(1 2 3) and (list 1 2 3) are :list[int], empty list is :list[nothing].
+
+// listOf returns type of list with elements of type elem.
+func (in *Interpret) listOf(elem Type) Type {
+	if elem == TypeNothing {
+		return Type("list[" + TypeNothing + "]")
+	}
+	return Type("list[" + in.typeArg(elem) + "]")
+}
+
+// literalType returns type of quoted list.
+func (in *Interpret) literalType(s *Sexpr) Type {
+	elem := TypeNothing
+	for _, p := range s.List {
+		t := p.T
+		switch a := p.V.(type) {
+		case Ident:
+			// symbol
+			t = TypeAny
+		case *Sexpr:
+			t = in.literalType(a)
+		}
+		elem = in.joinTypes(elem, t)
+	}
+	return in.listOf(elem)
+}
+
+// genType returns type of lazy list produced by generator with return type rt:
+// generator returns list of the next value and state so the type of values is
+// the type of elements of the list.
+func (in *Interpret) genType(rt Type) Type {
+	if in.UnaliasType(rt).Basic() != TypeList.Basic() || in.IsGeneric(rt) {
+		return TypeList
+	}
+	elem, err := in.elemType(rt, TypeList)
+	if err != nil || noTypeInfo(elem) {
+		return TypeList
+	}
+	return in.listOf(elem)
+}
+
+// Typers
+
+// ListArgs returns type of list which contains elements of the specified types.
+func (in *Interpret) ListArgs(params []Param) (Type, error) {
+	elem := TypeNothing
+	for _, p := range params {
+		elem = in.joinTypes(elem, p.T)
+	}
+	return in.listOf(elem), nil
+}
+
+// AppendArgs checks that values are appended to list or string and returns type of the result.
+func (in *Interpret) AppendArgs(params []Param) (Type, error) {
+	switch len(params) {
+	case 0:
+		return in.listOf(TypeNothing), nil
+	case 1:
+		return params[0].T, nil
+	}
+	t := in.UnaliasType(params[0].T)
+	if t == TypeUnknown || in.IsContract(t) {
+		return TypeList, nil
+	}
+	if ok, _ := in.canConvertType(t, TypeStr); ok {
+		return params[0].T, nil
+	}
+	if t.Basic() == TypeList.Basic() {
+		elem, err := in.elemType(t, TypeList)
+		if err != nil {
+			return TypeUnknown, err
+		}
+		if elem == TypeUnknown {
+			return TypeList, nil
+		}
+		for _, p := range params[1:] {
+			elem = in.joinTypes(elem, p.T)
+		}
+		return in.listOf(elem), nil
+	}
+	if t.Basic() == TypeVector.Basic() {
+		elem, err := in.vectorElem(t)
+		if err != nil {
+			return TypeUnknown, err
+		}
+		if elem == TypeUnknown {
+			return TypeVector, nil
+		}
+		for _, p := range params[1:] {
+			elem = in.joinTypes(elem, p.T)
+		}
+		return Type("vector[" + in.typeArg(elem) + "]"), nil
+	}
+	if ok, err := in.matchType(Type("list[a]"), t, &map[string]Type{}); ok {
+		// other types derived from list
+		return TypeList, nil
+	} else if err != nil {
+		return TypeUnknown, err
+	}
+	return TypeUnknown, fmt.Errorf("expected first argument to be list or string, found %v", params[0])
+}
@@ -0,0 +1,23 @@
+package spil
+
+import "testing"
+
+func TestListTypes(t *testing.T) {
+	sumInts := "(def sum ('()) :int 0)\n(def sum (l:list[int]) :int (+ (head l) (sum (tail l))))\n"
+	tests := []programTest{
+		{"literal", "(print (type '(1 2 3)) (type '(1 \"a\")) (type '(1 1.5)))", ":list[int] :list[any] :list[float]\n", ""},
+		{"empty", "(print (type '()) (type (list)))", ":list[nothing] :list[nothing]\n", ""},
+		{"nested", "(print (type '((1) (2))))", ":list[list[int]]\n", ""},
+		{"list", "(print (type (list 1 2 3)) (type (list \"a\" 1)))", ":list[int] :list[any]\n", ""},
+		{"append", "(print (type (append '() 1 2)) (type (append '(1) \"a\")) (type (append \"a\" \"b\")))", ":list[int] :list[any] :str\n", ""},
+		{"append vector", "(print (type (append (vector-of 1 2 3) 7)) (type (append (vector-of 1) 1.5)))", ":vector[int] :vector[float]\n", ""},
+		{"map", "(use std)\n(print (type (map (lambda (x:int) :str (str x)) '(1 2))) (type (map \\(str _1) '(1 2))))", ":list[str] :list\n", ""},
+		{"filter", "(use std)\n(print (type (filter (lambda (x:int) :bool (> x 1)) '(1 2))))", ":list[int]\n", ""},
+		{"gen", "(def next (n:int) :list[int] (list n (+ n 1)))\n(print (type (gen next 0)))", ":list[int]\n", ""},
+		{"checked", sumInts + "(print (sum (list 1 2 3)) (sum (append '(1) 2)))", "6 3\n", ""},
+		{"mismatch", sumInts + "(print (sum (list \"a\")))", "", "sum: no matching function implementation found for [{:list[str]"},
+		{"literal mismatch", sumInts + "(print (sum '(1 \"a\")))", "", "sum: no matching function implementation found"},
+		{"generic", "(def f (l:list[a] x:a) :list[a] (append l x))\n(print (type (f '() 1)))", ":list[int]\n", ""},
+	}
+	checkPrograms(t, tests)
+}
@@ -0,0 +1,294 @@
+package spil
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	identQuote      = Ident("quote")
+	identShortLamda = Ident(`\`)
+	// maximum number of nested macro expansions
+	maxMacroDepth = 1000
+)
+
+// (defmacro name (arg1 arg2 . rest) body...)
+// defines macro which is expanded at parse time.
+// Macro receives its arguments as unevaluated code (lists and atoms)
+// and returns code which replaces the macro call.
+// Arguments are matched like list patterns so macro may have several clauses.
+func (in *Interpret) defineMacro(se *Sexpr) error {
+	if se.Length() < 3 {
+		return fmt.Errorf("Not enough arguments for macro definition: %v", se)
+	}
+	name, ok := se.List[0].V.(Ident)
+	if !ok {
+		return fmt.Errorf("defmacro expected identifier first, found %v", se.List[0])
+	}
+	if _, ok := in.funcs[string(name)]; ok {
+		return fmt.Errorf("Cannot define macro %v: function with the same name already exists", string(name))
+	}
+	var pattern Param
+	switch a := se.List[1].V.(type) {
+	case Ident:
+		// all arguments are passed as list
+		pattern = se.List[1]
+	case *Sexpr:
+		if a.Quoted || a.Lambda {
+			return fmt.Errorf("defmacro %v: expected list of arguments, found %v", string(name), se.List[1])
+		}
+		pattern = Param{V: a, T: TypeList, Pos: se.List[1].Pos}
+	default:
+		return fmt.Errorf("defmacro %v: expected list of arguments, found %v", string(name), se.List[1])
+	}
+	mi, ok := in.macros[string(name)]
+	if !ok {
+		mi = NewFuncInterpret(in, string(name))
+	}
+	if err := mi.AddImpl(&Sexpr{List: []Param{pattern}}, se.List[2:], false, TypeUnknown); err != nil {
+		return err
+	}
+	in.macros[string(name)] = mi
+	return nil
+}
+
+// expandMacros replaces macro calls in the expression with their expansions.
+func (in *Interpret) expandMacros(p Param) (Param, error) {
+	for depth := 0; ; depth++ {
+		se, ok := p.V.(*Sexpr)
+		if !ok || se.Quoted || se.Empty() {
+			return p, nil
+		}
+		name, _ := se.List[0].V.(Ident)
+		mi, ok := in.macros[string(name)]
+		if !ok {
+			break
+		}
+		if depth >= maxMacroDepth {
+			return p, withPos(p.Pos, fmt.Errorf("macro %v: expansion is too deep", string(name)))
+		}
+		args := make([]Param, 0, len(se.List)-1)
+		for _, arg := range se.List[1:] {
+			args = append(args, toData(arg, false))
+		}
+		res, err := mi.Eval([]Param{{V: &Sexpr{List: args, Quoted: true}, T: TypeList}})
+		if err != nil {
+			return p, withPos(p.Pos, prefixErr(fmt.Sprintf("macro %v", string(name)), err))
+		}
+		if p, err = toCode(*res, p.Pos, false); err != nil {
+			return p, withPos(p.Pos, prefixErr(fmt.Sprintf("macro %v", string(name)), err))
+		}
+	}
+	se := p.V.(*Sexpr)
+	var skip []int
+	switch se.List[0].V {
+	case Ident("def"), Ident("def'"), Ident("func"), Ident("func'"):
+		// function name and arguments
+		skip = []int{1, 2}
+	case Ident("lambda"), Ident("defrecord"), Ident("defmacro"):
+		skip = []int{1}
+	case Ident("quasiquote"):
+		// template is expanded after it is instantiated
+		return p, nil
+	}
+	res := &Sexpr{List: make([]Param, len(se.List)), Lambda: se.Lambda}
+L:
+	for i, item := range se.List {
+		for _, s := range skip {
+			if i == s {
+				res.List[i] = item
+				continue L
+			}
+		}
+		var err error
+		if res.List[i], err = in.expandMacros(item); err != nil {
+			return p, err
+		}
+	}
+	return Param{V: res, T: p.T, Pos: p.Pos}, nil
+}
+
+// toData converts code into the value which is passed to macro:
+// quoted list '(1 2) becomes (quote (1 2)) and short lambda \(f _1) becomes (\ (f _1)).
+func toData(p Param, inQuote bool) Param {
+	se, ok := p.V.(*Sexpr)
+	if !ok {
+		return p
+	}
+	res := &Sexpr{List: make([]Param, 0, len(se.List)), Quoted: true}
+	for _, item := range se.List {
+		res.List = append(res.List, toData(item, inQuote || se.Quoted))
+	}
+	data := Param{V: res, T: TypeList, Pos: p.Pos}
+	switch {
+	case se.Quoted && !inQuote:
+		return Param{V: &Sexpr{List: []Param{{V: identQuote, T: TypeUnknown}, data}, Quoted: true}, T: TypeList, Pos: p.Pos}
+	case se.Lambda:
+		return Param{V: &Sexpr{List: []Param{{V: identShortLamda, T: TypeUnknown}, data}, Quoted: true}, T: TypeList, Pos: p.Pos}
+	}
+	return data
+}
+
+// toCode converts value returned by macro back to code.
+func toCode(p Param, pos *Pos, quoted bool) (Param, error) {
+	if p.Pos == nil {
+		p.Pos = pos
+	}
+	var list []Param
+	switch a := p.V.(type) {
+	case Str:
+		return p, nil
+	case *Sexpr:
+		list = a.List
+	case List:
+		for !a.Empty() {
+			h, err := a.Head()
+			if err != nil {
+				return p, err
+			}
+			list = append(list, *h)
+			if a, err = a.Tail(); err != nil {
+				return p, err
+			}
+		}
+	default:
+		return p, nil
+	}
+	if !quoted && len(list) == 2 && (list[0].V == identQuote || list[0].V == identShortLamda) {
+		code, err := toCode(list[1], p.Pos, list[0].V == identQuote)
+		if err != nil {
+			return p, err
+		}
+		se, ok := code.V.(*Sexpr)
+		if !ok {
+			return p, fmt.Errorf("%v expects list, found %v", list[0].V, list[1])
+		}
+		se.Lambda = list[0].V == identShortLamda
+		return code, nil
+	}
+	res := &Sexpr{List: make([]Param, len(list)), Quoted: quoted}
+	for i, item := range list {
+		var err error
+		if res.List[i], err = toCode(item, p.Pos, quoted); err != nil {
+			return p, err
+		}
+	}
+	return Param{V: res, T: TypeList, Pos: p.Pos}, nil
+}
+
+// (quasiquote template) builds list from template in which (unquote expr) is replaced
+// with the value of expr and elements of (unquote-splicing expr) are inserted into the list.
+// Identifiers ending with '#' are replaced with unique names (the same within one template)
+// so variables introduced by macro do not clash with variables of its caller.
+func (f *FuncRuntime) evalQuasiquote(se *Sexpr) (*Param, error) {
+	if len(se.List) != 2 {
+		return nil, fmt.Errorf("quasiquote expects 1 argument, found %v", se.List[1:])
+	}
+	res, err := f.quasiquote(se.List[1], map[string]Ident{})
+	if err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+func (f *FuncRuntime) quasiquote(tmpl Param, gensyms map[string]Ident) (Param, error) {
+	switch a := tmpl.V.(type) {
+	case Ident:
+		if s := string(a); len(s) > 1 && strings.HasSuffix(s, "#") {
+			sym, ok := gensyms[s]
+			if !ok {
+				sym = f.fi.interpret.gensym(strings.TrimSuffix(s, "#"))
+				gensyms[s] = sym
+			}
+			return Param{V: sym, T: TypeUnknown, Pos: tmpl.Pos}, nil
+		}
+		return tmpl, nil
+	case *Sexpr:
+		if len(a.List) == 2 && a.List[0].V == Ident("unquote") {
+			v, err := f.evalParameter(&a.List[1])
+			if err != nil {
+				return tmpl, err
+			}
+			return *v, nil
+		}
+		res := &Sexpr{List: make([]Param, 0, len(a.List)), Quoted: true}
+		for _, item := range a.List {
+			if s, ok := item.V.(*Sexpr); ok && len(s.List) == 2 && s.List[0].V == Ident("unquote-splicing") {
+				v, err := f.evalParameter(&s.List[1])
+				if err != nil {
+					return tmpl, err
+				}
+				l, ok := v.V.(List)
+				if !ok {
+					return tmpl, withPos(item.Pos, fmt.Errorf("unquote-splicing expects list, found %v", v))
+				}
+				for !l.Empty() {
+					h, err := l.Head()
+					if err != nil {
+						return tmpl, err
+					}
+					res.List = append(res.List, *h)
+					if l, err = l.Tail(); err != nil {
+						return tmpl, err
+					}
+				}
+				continue
+			}
+			v, err := f.quasiquote(item, gensyms)
+			if err != nil {
+				return tmpl, err
+			}
+			res.List = append(res.List, v)
+		}
+		data := Param{V: res, T: TypeList, Pos: tmpl.Pos}
+		switch {
+		case a.Quoted:
+			return Param{V: &Sexpr{List: []Param{{V: identQuote, T: TypeUnknown}, data}, Quoted: true}, T: TypeList, Pos: tmpl.Pos}, nil
+		case a.Lambda:
+			return Param{V: &Sexpr{List: []Param{{V: identShortLamda, T: TypeUnknown}, data}, Quoted: true}, T: TypeList, Pos: tmpl.Pos}, nil
+		}
+		return data, nil
+	}
+	return tmpl, nil
+}
+
+func (in *Interpret) gensym(prefix string) Ident {
+	in.gensymCount++
+	return Ident(fmt.Sprintf("__%v__%03d", prefix, in.gensymCount))
+}
+
+// (gensym) returns unique identifier
+func (in *Interpret) FGensym(args []Param) (*Param, error) {
+	if len(args) != 0 {
+		return nil, fmt.Errorf("FGensym: expected no arguments, found %v", args)
+	}
+	return &Param{V: in.gensym("gensym"), T: TypeAny}, nil
+}
+
+// Typers
+
+// quasiquoteType checks types of unquoted expressions of the template.
+func (in *Interpret) quasiquoteType(fname string, tmpl Param, vars map[string]Type) error {
+	a, ok := tmpl.V.(*Sexpr)
+	if !ok {
+		return nil
+	}
+	if len(a.List) == 2 && (a.List[0].V == Ident("unquote") || a.List[0].V == Ident("unquote-splicing")) {
+		t, err := in.exprType(fname, a.List[1], vars)
+		if err != nil {
+			return err
+		}
+		if a.List[0].V == Ident("unquote-splicing") && t != TypeUnknown && t != TypeAny && t != TypeStr {
+			if _, err := in.elemType(t, TypeList); err != nil {
+				return withPos(tmpl.Pos, fmt.Errorf("%v: unquote-splicing expects list, found: %v", fname, t))
+			}
+		}
+		return nil
+	}
+	for _, item := range a.List {
+		if err := in.quasiquoteType(fname, item, vars); err != nil {
+			return err
+		}
+	}
+	return nil
+}
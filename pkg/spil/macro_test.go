@@ -0,0 +1,22 @@
+package spil
+
+import "testing"
+
+func TestMacros(t *testing.T) {
+	tests := []programTest{
+		{"when", "(defmacro when (c . body) `(if ,c (do ,@body) '()))\n(when true (print 1) (print 2))\n(when false (print 3))", "1\n2\n", ""},
+		{"clauses", "(defmacro m () 0)\n(defmacro m (x) x)\n(defmacro m (x . r) `(+ ,x (m ,@r)))\n(print (m 1 2 3) (m))", "6 0\n", ""},
+		{"quoted args", "(defmacro second (l) `(native.head (native.tail ,l)))\n(print (second '(1 2 3)))", "2\n", ""},
+		{"short lambda", "(def app (f:func[int,int] x:int) :int (f x))\n(defmacro call (f x) `(app ,f ,x))\n(print (call \\(+ _1 1) 1))", "2\n", ""},
+		{"in function", "(defmacro twice (x) `(+ ,x ,x))\n(def f (n:int) :int (twice n))\n(print (f 4))", "8\n", ""},
+		{"defines function", "(defmacro defconst (name v) `(def ,name () ,v))\n(defconst five 5)\n(print (five))", "5\n", ""},
+		{"hygiene", "(defmacro with-x (e) `(let ((x# 10)) (+ x# ,e)))\n(set x 1)\n(print (with-x x))", "11\n", ""},
+		{"no match", "(defmacro m (x) x)\n(m 1 2)", "", "macro m:"},
+		{"recursion", "(defmacro loop (x) `(loop ,x))\n(loop 1)", "", "macro loop: expansion is too deep"},
+		{"function name", "(defmacro print (x) x)", "", "function with the same name already exists"},
+		{"macro name", "(defmacro m (x) x)\n(def m (x) x)", "", "macro with the same name already exists"},
+		{"unquote", "(print (unquote 1))", "", "unquote outside of quasiquote"},
+		{"runtime quasiquote", "(set x 2)\n(print `(1 ,x ,@(list 3 4) (5 ,x)))", "'(1 2 3 4 '(5 2))\n", ""},
+	}
+	checkPrograms(t, tests)
+}
@@ -0,0 +1,392 @@
+package spil
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Map is an immutable associative array.
+type Map struct {
+	h *hamt
+}
+
+var _ Expr = (*Map)(nil)
+var _ Lenghter = (*Map)(nil)
+
+var EmptyMap = &Map{emptyHamt}
+
+func (m *Map) String() string {
+	b := &strings.Builder{}
+	b.WriteString("{Map:")
+	m.h.each(func(e *hamtEntry) error {
+		fmt.Fprintf(b, " %v => %v", e.key.V, e.value.V)
+		return nil
+	})
+	b.WriteString("}")
+	return b.String()
+}
+
+// Hash of map does not depend on the order in which keys were added.
+func (m *Map) Hash() (string, error) {
+	b := &strings.Builder{}
+	b.WriteString("{Map:")
+	err := m.h.each(func(e *hamtEntry) error {
+		vh, err := e.value.V.Hash()
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(b, " %v => %v", e.hash, vh)
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	b.WriteString("}")
+	return b.String(), nil
+}
+
+func (m *Map) Print(w io.Writer) {
+	io.WriteString(w, "{")
+	first := true
+	m.h.each(func(e *hamtEntry) error {
+		if !first {
+			io.WriteString(w, ", ")
+		}
+		first = false
+		e.key.V.Print(w)
+		io.WriteString(w, " ")
+		e.value.V.Print(w)
+		return nil
+	})
+	io.WriteString(w, "}")
+}
+
+func (m *Map) Type() Type {
+	return "map[any,any]"
+}
+
+func (m *Map) Length() int {
+	return m.h.size
+}
+
+func (m *Map) Get(key Param) (*Param, bool, error) {
+	e, err := m.h.get(key)
+	if err != nil || e == nil {
+		return nil, false, err
+	}
+	return &e.value, true, nil
+}
+
+func (m *Map) Put(key, value Param) (*Map, error) {
+	e, err := newHamtEntry(key, value)
+	if err != nil {
+		return nil, err
+	}
+	return &Map{m.h.put(e)}, nil
+}
+
+func (m *Map) Remove(key Param) (*Map, error) {
+	h, err := m.h.remove(key)
+	if err != nil {
+		return nil, err
+	}
+	return &Map{h}, nil
+}
+
+func (m *Map) each(fn func(key, value *Param) error) error {
+	return m.h.each(func(e *hamtEntry) error {
+		return fn(&e.key, &e.value)
+	})
+}
+
+// Native functions
+
+// (map-of key1 value1 key2 value2 ...)
+func (in *Interpret) FMapOf(args []Param) (*Param, error) {
+	t, err := in.MapOfArgs(args)
+	if err != nil {
+		return nil, fmt.Errorf("FMapOf: %w", err)
+	}
+	k, v, _ := in.mapTypes(t)
+	m := EmptyMap
+	for i := 0; i < len(args); i += 2 {
+		m, err = m.Put(*in.promote(&args[i], k), *in.promote(&args[i+1], v))
+		if err != nil {
+			return nil, fmt.Errorf("FMapOf: %w", err)
+		}
+	}
+	return &Param{V: m, T: t}, nil
+}
+
+// (map-get map key [default])
+func (in *Interpret) FMapGet(args []Param) (*Param, error) {
+	if len(args) != 2 && len(args) != 3 {
+		return nil, fmt.Errorf("FMapGet: expected 2 or 3 arguments, found %v", args)
+	}
+	m, ok := args[0].V.(*Map)
+	if !ok {
+		return nil, fmt.Errorf("FMapGet: expected first argument to be Map, found %v", args[0])
+	}
+	v, ok, err := m.Get(args[1])
+	if err != nil {
+		return nil, fmt.Errorf("FMapGet: %w", err)
+	}
+	if ok {
+		return v, nil
+	}
+	if len(args) == 3 {
+		return &args[2], nil
+	}
+	b := &strings.Builder{}
+	args[1].V.Print(b)
+	return nil, fmt.Errorf("FMapGet: key not found: %v", b.String())
+}
+
+// (map-put map key value)
+func (in *Interpret) FMapPut(args []Param) (*Param, error) {
+	if len(args) != 3 {
+		return nil, fmt.Errorf("FMapPut: expected 3 arguments, found %v", args)
+	}
+	m, ok := args[0].V.(*Map)
+	if !ok {
+		return nil, fmt.Errorf("FMapPut: expected first argument to be Map, found %v", args[0])
+	}
+	k, v, _ := in.mapTypes(args[0].T)
+	res, err := m.Put(*in.promote(&args[1], k), *in.promote(&args[2], v))
+	if err != nil {
+		return nil, fmt.Errorf("FMapPut: %w", err)
+	}
+	return &Param{V: res, T: args[0].T}, nil
+}
+
+// (map-remove map key)
+func (in *Interpret) FMapRemove(args []Param) (*Param, error) {
+	if len(args) != 2 {
+		return nil, fmt.Errorf("FMapRemove: expected 2 arguments, found %v", args)
+	}
+	m, ok := args[0].V.(*Map)
+	if !ok {
+		return nil, fmt.Errorf("FMapRemove: expected first argument to be Map, found %v", args[0])
+	}
+	res, err := m.Remove(args[1])
+	if err != nil {
+		return nil, fmt.Errorf("FMapRemove: %w", err)
+	}
+	return &Param{V: res, T: args[0].T}, nil
+}
+
+// (map-contains map key)
+func (in *Interpret) FMapContains(args []Param) (*Param, error) {
+	if len(args) != 2 {
+		return nil, fmt.Errorf("FMapContains: expected 2 arguments, found %v", args)
+	}
+	m, ok := args[0].V.(*Map)
+	if !ok {
+		return nil, fmt.Errorf("FMapContains: expected first argument to be Map, found %v", args[0])
+	}
+	_, ok, err := m.Get(args[1])
+	if err != nil {
+		return nil, fmt.Errorf("FMapContains: %w", err)
+	}
+	return &Param{V: Bool(ok), T: TypeBool}, nil
+}
+
+// (map-keys map)
+func (in *Interpret) FMapKeys(args []Param) (*Param, error) {
+	return in.mapList("FMapKeys", args, func(k, v *Param) Param { return *k }, 0)
+}
+
+// (map-values map)
+func (in *Interpret) FMapValues(args []Param) (*Param, error) {
+	return in.mapList("FMapValues", args, func(k, v *Param) Param { return *v }, 1)
+}
+
+// (map-items map) returns list of (key value) pairs
+func (in *Interpret) FMapItems(args []Param) (*Param, error) {
+	return in.mapList("FMapItems", args, func(k, v *Param) Param {
+		return Param{V: QList(*k, *v), T: TypeList}
+	}, -1)
+}
+
+// mapList converts map into list. elem is index of list element type in the map type (or -1 for pairs).
+func (in *Interpret) mapList(name string, args []Param, item func(k, v *Param) Param, elem int) (*Param, error) {
+	if len(args) != 1 {
+		return nil, fmt.Errorf("%v: expected 1 argument, found %v", name, args)
+	}
+	m, ok := args[0].V.(*Map)
+	if !ok {
+		return nil, fmt.Errorf("%v: expected argument to be Map, found %v", name, args[0])
+	}
+	res := &Sexpr{Quoted: true}
+	m.each(func(k, v *Param) error {
+		res.List = append(res.List, item(k, v))
+		return nil
+	})
+	t, err := in.mapListType(args[0].T, elem)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %w", name, err)
+	}
+	return &Param{V: res, T: t}, nil
+}
+
+// (map-size map)
+func (in *Interpret) FMapSize(args []Param) (*Param, error) {
+	if len(args) != 1 {
+		return nil, fmt.Errorf("FMapSize: expected 1 argument, found %v", args)
+	}
+	l, ok := args[0].V.(Lenghter)
+	if !ok {
+		return nil, fmt.Errorf("FMapSize: expected argument to be Map, found %v", args[0])
+	}
+	return &Param{V: in.intMaker.MakeInt(int64(l.Length())), T: TypeInt}, nil
+}
+
+// Typers
+
+// mapTypes returns types of keys and values of the map type.
+func (in *Interpret) mapTypes(t Type) (Type, Type, error) {
+	if t == TypeUnknown || in.IsGeneric(t) {
+		return TypeUnknown, TypeUnknown, nil
+	}
+	p, err := in.toParent(in.UnaliasType(t), TypeMap)
+	if err != nil {
+		return TypeUnknown, TypeUnknown, fmt.Errorf("expected map, found %v", t)
+	}
+	args := p.Arguments()
+	if len(args) != 2 {
+		return TypeUnknown, TypeUnknown, fmt.Errorf("expected map, found %v", t)
+	}
+	return args[0], args[1], nil
+}
+
+// typeArg converts type into argument of generic type.
+func (in *Interpret) typeArg(t Type) Type {
+	if noTypeInfo(t) {
+		return TypeAny
+	}
+	return t
+}
+
+func (in *Interpret) expectType(what string, p Param, t Type) error {
+	if p.T == TypeUnknown || t == TypeUnknown || in.IsGeneric(p.T) {
+		return nil
+	}
+	ok, err := in.canConvertType(p.T, t)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("expected %v to be %v, found %v", what, t, p.T)
+	}
+	return nil
+}
+
+// MapOfArgs checks that map-of is called with pairs of keys and values and returns type of the map.
+func (in *Interpret) MapOfArgs(params []Param) (Type, error) {
+	if len(params)%2 != 0 {
+		return TypeUnknown, fmt.Errorf("expected even number of arguments, found %v", params)
+	}
+	k, v := TypeNothing, TypeNothing
+	for i := 0; i < len(params); i += 2 {
+		k = in.joinTypes(k, params[i].T)
+		v = in.joinTypes(v, params[i+1].T)
+	}
+	return Type("map[" + in.typeArg(k) + "," + in.typeArg(v) + "]"), nil
+}
+
+func (in *Interpret) MapGetArgs(params []Param) (Type, error) {
+	if len(params) != 2 && len(params) != 3 {
+		return TypeUnknown, fmt.Errorf("expected 2 or 3 arguments, found %v", params)
+	}
+	k, v, err := in.mapTypes(params[0].T)
+	if err != nil {
+		return TypeUnknown, err
+	}
+	if err := in.expectType("key", params[1], k); err != nil {
+		return TypeUnknown, err
+	}
+	if len(params) == 3 {
+		return in.joinTypes(v, params[2].T), nil
+	}
+	return v, nil
+}
+
+func (in *Interpret) MapPutArgs(params []Param) (Type, error) {
+	if len(params) != 3 {
+		return TypeUnknown, fmt.Errorf("expected 3 arguments, found %v", params)
+	}
+	k, v, err := in.mapTypes(params[0].T)
+	if err != nil {
+		return TypeUnknown, err
+	}
+	if err := in.expectType("key", params[1], k); err != nil {
+		return TypeUnknown, err
+	}
+	if err := in.expectType("value", params[2], v); err != nil {
+		return TypeUnknown, err
+	}
+	return params[0].T, nil
+}
+
+func (in *Interpret) MapKeyArgs(params []Param) (Type, error) {
+	if len(params) != 2 {
+		return TypeUnknown, fmt.Errorf("expected 2 arguments, found %v", params)
+	}
+	k, _, err := in.mapTypes(params[0].T)
+	if err != nil {
+		return TypeUnknown, err
+	}
+	if err := in.expectType("key", params[1], k); err != nil {
+		return TypeUnknown, err
+	}
+	return params[0].T, nil
+}
+
+func (in *Interpret) MapContainsArgs(params []Param) (Type, error) {
+	if _, err := in.MapKeyArgs(params); err != nil {
+		return TypeUnknown, err
+	}
+	return TypeBool, nil
+}
+
+func (in *Interpret) mapListType(t Type, elem int) (Type, error) {
+	k, v, err := in.mapTypes(t)
+	if err != nil {
+		return TypeUnknown, err
+	}
+	switch {
+	case elem == 0 && k != TypeUnknown:
+		return Type("list[" + k + "]"), nil
+	case elem == 1 && v != TypeUnknown:
+		return Type("list[" + v + "]"), nil
+	}
+	return TypeList, nil
+}
+
+func (in *Interpret) MapKeysArgs(params []Param) (Type, error) {
+	return in.mapListArgs(params, 0)
+}
+
+func (in *Interpret) MapValuesArgs(params []Param) (Type, error) {
+	return in.mapListArgs(params, 1)
+}
+
+func (in *Interpret) MapItemsArgs(params []Param) (Type, error) {
+	return in.mapListArgs(params, -1)
+}
+
+func (in *Interpret) mapListArgs(params []Param, elem int) (Type, error) {
+	if len(params) != 1 {
+		return TypeUnknown, fmt.Errorf("expected 1 argument, found %v", params)
+	}
+	return in.mapListType(params[0].T, elem)
+}
+
+func (in *Interpret) MapSizeArgs(params []Param) error {
+	if len(params) != 1 {
+		return fmt.Errorf("expected 1 argument, found %v", params)
+	}
+	_, _, err := in.mapTypes(params[0].T)
+	return err
+}
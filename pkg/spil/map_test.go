@@ -0,0 +1,134 @@
+package spil
+
+import (
+	"fmt"
+	"testing"
+)
+
+func intParam(i int) Param {
+	return Param{V: Int64(i), T: TypeInt}
+}
+
+func TestMapPutGetRemove(t *testing.T) {
+	const n = 2000
+	m := EmptyMap
+	var err error
+	for i := 0; i < n; i++ {
+		m, err = m.Put(intParam(i), Param{V: Str(fmt.Sprint(i)), T: TypeStr})
+		if err != nil {
+			t.Fatalf("Put() failed: %v", err)
+		}
+	}
+	if m.Length() != n {
+		t.Fatalf("Incorrect map size: expected %v, actual %v", n, m.Length())
+	}
+	// replace existing key
+	m, _ = m.Put(intParam(7), Param{V: Str("seven"), T: TypeStr})
+	if m.Length() != n {
+		t.Errorf("Size of map changed after replacing value: %v", m.Length())
+	}
+	for i := 0; i < n; i++ {
+		v, ok, err := m.Get(intParam(i))
+		if err != nil || !ok {
+			t.Fatalf("Get(%v) failed: %v, %v", i, ok, err)
+		}
+		exp := Str(fmt.Sprint(i))
+		if i == 7 {
+			exp = "seven"
+		}
+		if v.V != exp {
+			t.Errorf("Incorrect value for key %v: expected %v, actual %v", i, exp, v.V)
+		}
+	}
+	if _, ok, _ := m.Get(intParam(n)); ok {
+		t.Errorf("Unexpected key found: %v", n)
+	}
+
+	removed := m
+	for i := 0; i < n; i += 2 {
+		removed, err = removed.Remove(intParam(i))
+		if err != nil {
+			t.Fatalf("Remove() failed: %v", err)
+		}
+	}
+	if removed.Length() != n/2 {
+		t.Errorf("Incorrect map size after removal: expected %v, actual %v", n/2, removed.Length())
+	}
+	for i := 0; i < n; i++ {
+		_, ok, _ := removed.Get(intParam(i))
+		if ok != (i%2 == 1) {
+			t.Errorf("Incorrect presence of key %v after removal: %v", i, ok)
+		}
+		// original map is not changed
+		if _, ok, _ := m.Get(intParam(i)); !ok {
+			t.Errorf("Key %v disappeared from original map", i)
+		}
+	}
+}
+
+func TestMapHashIsOrderIndependent(t *testing.T) {
+	m1, m2 := EmptyMap, EmptyMap
+	for i := 0; i < 100; i++ {
+		m1, _ = m1.Put(intParam(i), intParam(i*i))
+		m2, _ = m2.Put(intParam(99-i), intParam((99-i)*(99-i)))
+	}
+	// add and remove some extra keys
+	for i := 100; i < 150; i++ {
+		m2, _ = m2.Put(intParam(i), intParam(0))
+	}
+	for i := 100; i < 150; i++ {
+		m2, _ = m2.Remove(intParam(i))
+	}
+	h1, err := m1.Hash()
+	if err != nil {
+		t.Fatalf("Hash() failed: %v", err)
+	}
+	h2, err := m2.Hash()
+	if err != nil {
+		t.Fatalf("Hash() failed: %v", err)
+	}
+	if h1 != h2 {
+		t.Errorf("Hashes of equal maps differ:\n%v\n%v", h1, h2)
+	}
+	if !Equal(m1, m2) {
+		t.Errorf("Equal maps are not equal")
+	}
+	m3, _ := m2.Put(intParam(0), intParam(1))
+	if Equal(m1, m3) {
+		t.Errorf("Different maps are equal")
+	}
+}
+
+func TestHamtCollisions(t *testing.T) {
+	h := emptyHamt
+	// entries with the same hash code are stored in one bucket
+	for _, key := range []string{"c", "a", "b"} {
+		h = h.put(hamtEntry{hash: key, code: 42, key: Param{V: Str(key)}})
+	}
+	h = h.put(hamtEntry{hash: "d", code: 42 + 1<<hamtBits, key: Param{V: Str("d")}})
+	if h.size != 4 {
+		t.Fatalf("Incorrect size: %v", h.size)
+	}
+	if act, exp := hamtKeys(h.root), "[a b c d]"; act != exp {
+		t.Errorf("Incorrect order of entries: expected %v, actual %v", exp, act)
+	}
+	root, removed := h.root.remove(hamtEntry{hash: "b", code: 42}, 0)
+	if !removed {
+		t.Fatalf("Entry was not removed")
+	}
+	if act, exp := hamtKeys(root), "[a c d]"; act != exp {
+		t.Errorf("Incorrect entries after removal: expected %v, actual %v", exp, act)
+	}
+	if _, removed := root.remove(hamtEntry{hash: "b", code: 42}, 0); removed {
+		t.Errorf("Entry was removed twice")
+	}
+}
+
+func hamtKeys(n *hamtNode) string {
+	var keys []string
+	n.each(func(e *hamtEntry) error {
+		keys = append(keys, e.hash)
+		return nil
+	})
+	return fmt.Sprint(keys)
+}
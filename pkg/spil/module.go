@@ -0,0 +1,90 @@
+package spil
+
+import (
+	"fmt"
+)
+
+// Native functions and modules provided by host application.
+
+// Module is a named group of native functions which are loaded with (use <name>) directive.
+type Module map[string]Evaler
+
+// Directives of 'use' which cannot be overridden by modules.
+var useDirectives = map[string]bool{
+	"bigmath":  true,
+	"rational": true,
+	"std":      true,
+	"strict":   true,
+}
+
+// RegisterFunc makes native function available in spil programs under the specified name.
+// binder is used by type checker to validate arguments of the call, ret is the function return type.
+func (in *Interpret) RegisterFunc(name string, fn func([]Param) (*Param, error), binder func([]Param) error, ret Type) error {
+	if binder == nil {
+		binder = AnyArgs
+	}
+	return in.registerEvaler(name, EvalerFunc(name, fn, binder, ret))
+}
+
+// RegisterModule registers group of native functions which is loaded with (use <name>).
+func (in *Interpret) RegisterModule(name string, funcs Module) error {
+	if useDirectives[name] {
+		return fmt.Errorf("Cannot register module %v: name is reserved", name)
+	}
+	if _, ok := in.modules[name]; ok {
+		return fmt.Errorf("Module %v is already registered", name)
+	}
+	in.modules[name] = funcs
+	return nil
+}
+
+func (in *Interpret) registerEvaler(name string, fn Evaler) error {
+	if _, ok := in.funcs[name]; ok {
+		return fmt.Errorf("Cannot register function %v: it is already defined", name)
+	}
+	in.funcs[name] = fn
+	return nil
+}
+
+func (in *Interpret) loadModule(name string) (bool, error) {
+	module, ok := in.modules[name]
+	if !ok {
+		return false, nil
+	}
+	if in.loadedModules[name] {
+		return true, nil
+	}
+	// module is loaded only if all of its functions can be registered
+	for fname := range module {
+		if _, ok := in.funcs[fname]; ok {
+			return true, fmt.Errorf("Cannot load module %v: cannot register function %v: it is already defined", name, fname)
+		}
+	}
+	for fname, fn := range module {
+		in.funcs[fname] = fn
+	}
+	in.loadedModules[name] = true
+	return true, nil
+}
+
+// ArgTypes returns binder which checks that function is called with arguments of the specified types.
+func (in *Interpret) ArgTypes(types ...Type) func([]Param) error {
+	return func(params []Param) error {
+		if len(params) != len(types) {
+			return fmt.Errorf("expected %v arguments, found %v", len(types), params)
+		}
+		for i, p := range params {
+			if p.T == TypeUnknown || in.IsContract(p.T) {
+				continue
+			}
+			ok, err := in.matchType(types[i], p.T, &map[string]Type{})
+			if err != nil {
+				return err
+			}
+			if !ok {
+				return fmt.Errorf("expected argument %v to be %v, found %v", i, types[i], p)
+			}
+		}
+		return nil
+	}
+}
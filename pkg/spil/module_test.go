@@ -0,0 +1,115 @@
+package spil
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func repeatStr(args []Param) (*Param, error) {
+	s := args[0].V.(Str)
+	n := args[1].V.(Int)
+	return &Param{V: Str(strings.Repeat(string(s), int(n.Int64()))), T: TypeStr}, nil
+}
+
+func TestRegisterFunc(t *testing.T) {
+	out := &strings.Builder{}
+	in := NewInterpreter(out, getTestLibraryDir())
+	if err := in.RegisterFunc("repeat", repeatStr, in.ArgTypes(TypeStr, TypeInt), TypeStr); err != nil {
+		t.Fatalf("RegisterFunc() failed: %v", err)
+	}
+	if err := in.RegisterFunc("+", repeatStr, nil, TypeStr); err == nil {
+		t.Errorf("RegisterFunc() should fail on redefinition of builtin function")
+	}
+	if err := in.Parse("__test__", strings.NewReader(`(print (repeat "ab" 3))`)); err != nil {
+		t.Fatalf("Parse() failed: %v", err)
+	}
+	if errs := in.Check(); len(errs) > 0 {
+		t.Fatalf("Check() failed: %v", errs)
+	}
+	if err := in.Run(nil, nil); err != nil {
+		t.Fatalf("Run() failed: %v", err)
+	}
+	if act, exp := out.String(), "ababab\n"; act != exp {
+		t.Errorf("Incorrect output: expected %q, actual %q", exp, act)
+	}
+}
+
+func TestRegisterFuncTypecheck(t *testing.T) {
+	in := NewInterpreter(&strings.Builder{}, getTestLibraryDir())
+	if err := in.RegisterFunc("repeat", repeatStr, in.ArgTypes(TypeStr, TypeInt), TypeStr); err != nil {
+		t.Fatalf("RegisterFunc() failed: %v", err)
+	}
+	if err := in.Parse("__test__", strings.NewReader(`(print (repeat 3 "ab"))`)); err != nil {
+		t.Fatalf("Parse() failed: %v", err)
+	}
+	if errs := in.Check(); len(errs) == 0 {
+		t.Errorf("Check() should fail for incorrect argument types")
+	}
+}
+
+func TestRegisterModule(t *testing.T) {
+	tests := []struct {
+		src    string
+		output string
+		err    bool
+	}{
+		{"(use strutil)\n(print (repeat \"x\" 2))", "xx\n", false},
+		{"(use strutil)\n(use strutil)\n(print (repeat \"x\" 2))", "xx\n", false},
+		{"(print (repeat \"x\" 2))", "", true},
+		{"(use unknown)", "", true},
+	}
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("test-%v", i), func(t *testing.T) {
+			out := &strings.Builder{}
+			in := NewInterpreter(out, getTestLibraryDir())
+			err := in.RegisterModule("strutil", Module{
+				"repeat": EvalerFunc("repeat", repeatStr, in.ArgTypes(TypeStr, TypeInt), TypeStr),
+			})
+			if err != nil {
+				t.Fatalf("RegisterModule() failed: %v", err)
+			}
+			err = in.Parse("__test__", strings.NewReader(test.src))
+			if err == nil {
+				if errs := in.Check(); len(errs) > 0 {
+					err = errs[0]
+				}
+			}
+			if err == nil {
+				err = in.Run(nil, nil)
+			}
+			if test.err {
+				if err == nil {
+					t.Errorf("Error expected")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if act := out.String(); act != test.output {
+				t.Errorf("Incorrect output: expected %q, actual %q", test.output, act)
+			}
+		})
+	}
+}
+
+func TestLoadModuleConflict(t *testing.T) {
+	in := NewInterpreter(&strings.Builder{}, getTestLibraryDir())
+	err := in.RegisterModule("broken", Module{
+		"repeat": EvalerFunc("repeat", repeatStr, in.ArgTypes(TypeStr, TypeInt), TypeStr),
+		"print":  EvalerFunc("print", repeatStr, nil, TypeStr),
+	})
+	if err != nil {
+		t.Fatalf("RegisterModule() failed: %v", err)
+	}
+	if _, err := in.loadModule("broken"); err == nil {
+		t.Fatalf("loadModule() should fail on redefinition of builtin function")
+	}
+	if _, ok := in.funcs["repeat"]; ok {
+		t.Errorf("Functions of module which failed to load should not be registered")
+	}
+	if in.loadedModules["broken"] {
+		t.Errorf("Module which failed to load should not be marked as loaded")
+	}
+}
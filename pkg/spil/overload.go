@@ -0,0 +1,85 @@
+package spil
+
+// Overloading: implementation of function with more specific argument types
+// takes precedence over implementations declared before it, e.g.
+// (def length (s:set) :int ...) is chosen over (def length (l:list) :int ...) for :set values
+// even if it is declared later in another file.
+
+// moreSpecificType returns true if t1 is a proper subtype of t2.
+// Concrete types are more specific than type variables, and any type is more specific than :any.
+func (in *Interpret) moreSpecificType(t1, t2 Type) bool {
+	t1, t2 = in.UnaliasType(t1), in.UnaliasType(t2)
+	if t1 == t2 || t1 == TypeUnknown || t1 == TypeAny {
+		return false
+	}
+	if t2 == TypeUnknown || t2 == TypeAny {
+		return true
+	}
+	if in.IsGeneric(t1) {
+		return false
+	}
+	if in.IsGeneric(t2) {
+		return true
+	}
+	// value of type t1 can be passed where t2 is expected but not vice versa
+	to, _ := in.matchType(t2, t1, &map[string]Type{})
+	from, _ := in.matchType(t1, t2, &map[string]Type{})
+	return to && !from
+}
+
+// moreSpecificImpl returns true if argument types of a are not wider than types of b
+// and at least one of them is narrower. Implementations with literal or pattern arguments
+// are never overridden.
+func (in *Interpret) moreSpecificImpl(a, b *ArgFmt) bool {
+	if a == nil || b == nil || a.Wildcard != "" || b.Wildcard != "" || len(a.Args) != len(b.Args) {
+		return false
+	}
+	narrower := false
+	for i := range a.Args {
+		if b.Args[i].V != nil || b.Args[i].Pattern != nil {
+			// literals and patterns are more specific than types
+			return false
+		}
+		ta, tb := a.Args[i].T, b.Args[i].T
+		if in.UnaliasType(ta) == in.UnaliasType(tb) {
+			continue
+		}
+		if !in.moreSpecificType(ta, tb) {
+			return false
+		}
+		narrower = true
+	}
+	return narrower
+}
+
+// sameSignature returns true if implementations with argument formats a and b
+// accept exactly the same arguments.
+func (in *Interpret) sameSignature(a, b *ArgFmt) bool {
+	if a == nil || b == nil || a.Wildcard != "" || b.Wildcard != "" {
+		return a == nil && b == nil
+	}
+	if len(a.Args) != len(b.Args) {
+		return false
+	}
+	for i := range a.Args {
+		x, y := a.Args[i], b.Args[i]
+		if x.V != nil || y.V != nil || x.Pattern != nil || y.Pattern != nil {
+			return false
+		}
+		if in.UnaliasType(x.T) != in.UnaliasType(y.T) {
+			return false
+		}
+	}
+	return true
+}
+
+// setOverrides marks implementations declared before the last one which it overrides.
+func (f *FuncInterpret) setOverrides() {
+	last := f.bodies[len(f.bodies)-1]
+	last.overrides = map[int]bool{}
+	for i, im := range f.bodies[:len(f.bodies)-1] {
+		if f.interpret.moreSpecificImpl(last.argfmt, im.argfmt) {
+			last.overrides[i] = true
+		}
+	}
+}
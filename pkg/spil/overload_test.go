@@ -0,0 +1,20 @@
+package spil
+
+import "testing"
+
+func TestOverloading(t *testing.T) {
+	bag := "(use std)\n(deftype :bag :list)\n(set b (do '(1 2) :bag))\n"
+	tests := []programTest{
+		{"user type", bag + "(def d (x:list) :str \"list\")\n(def d (x:bag) :str \"bag\")\n(print (d b) (d '(1)))", "bag list\n", ""},
+		{"library function", bag + "(def length (x:bag) :int 42)\n(print (length b) (length '(1 2 3)))", "42 3\n", ""},
+		{"same signature in other file", "(use std)\n(def length (l:list) :int 0)", "", "cannot define function 'length' (l:list) in file test.lisp: it is already defined in"},
+		{"numeric tower", "(def d (x:float) :str \"float\")\n(def d (x:int) :str \"int\")\n(print (d 1) (d 1.5))", "int float\n", ""},
+		{"generic", "(def d (x:a) :str \"a\")\n(def d (x:int) :str \"int\")\n(print (d 1) (d \"s\"))", "int a\n", ""},
+		{"first declared", "(def d (x:int) :str \"int\")\n(def d (x:any) :str \"any\")\n(print (d 1) (d \"s\"))", "int any\n", ""},
+		{"literal is not overridden", "(def d ('()) :str \"empty\")\n(def d (s:str) :str \"str\")\n(print (d \"\") (d \"a\"))", "empty str\n", ""},
+		{"guard", "(def d (x:any) :str \"any\")\n(def d (x:int) :str when (> x 0) \"positive\")\n(print (d 1) (d -1))", "positive any\n", ""},
+		{"native builtin", bag + "(def print (x:bag) :any (print \"bag\"))\n(print b)\n(print 1 \"a\")", "bag\n1 a\n", ""},
+		{"type check", bag + "(def d (x:list) :int 1)\n(def d (x:bag) :str \"bag\")", "", "cannot redefine return type"},
+	}
+	checkPrograms(t, tests)
+}
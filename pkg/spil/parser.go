@@ -1,8 +1,9 @@
-package main
+package spil
 
 import (
 	"bufio"
 	"errors"
+	"fmt"
 	"io"
 	"strings"
 	"unicode"
@@ -25,6 +26,15 @@ func (f IntParserFn) ParseInt(token string) (Int, bool) {
 type Parser struct {
 	scanner *bufio.Scanner
 	tokens  []string
+	// positions of tokens
+	positions []Pos
+
+	// name of the parsed file
+	file string
+	// number of the current line
+	line int
+	// position of the last token returned by nextToken()
+	pos Pos
 
 	intParser IntParser
 }
@@ -43,33 +53,51 @@ func (p *Parser) NextExpr() (*Param, error) {
 	if err != nil {
 		return nil, err
 	}
+	if token == ")" {
+		return nil, &PosError{Pos: p.pos, Err: fmt.Errorf("Unexpected ')'")}
+	}
+	return p.parseExpr(token, false)
+}
+
+// reader syntax for quasiquotes: `x => (quasiquote x), ,x => (unquote x), ,@x => (unquote-splicing x)
+var readerForms = []struct {
+	prefix string
+	form   Ident
+}{
+	{",@", "unquote-splicing"},
+	{",", "unquote"},
+	{"`", "quasiquote"},
+}
+
+// parseExpr parses expression started with the token (which is not ')').
+func (p *Parser) parseExpr(token string, quoted bool) (*Param, error) {
 	if token == "(" || token == "'(" || token == "\\(" {
-		item, err := p.nextSexpr(token, token == "'(")
+		return p.nextSexpr(token, quoted || token == "'(")
+	}
+	for _, rf := range readerForms {
+		if !strings.HasPrefix(token, rf.prefix) || len(token) == len(rf.prefix) {
+			continue
+		}
+		pos := p.pos
+		item, err := p.parseExpr(token[len(rf.prefix):], quoted)
 		if err != nil {
 			return nil, err
 		}
-		return item, nil
-	}
-	if token == "'T" || token == "'F" || token == "true" || token == "false" {
-		v := token == "'T" || token == "true"
-		return &Param{V: Bool(v), T: TypeBool}, nil
-	}
-	if n, ok := p.intParser.ParseInt(token); ok {
-		return &Param{V: n, T: TypeInt}, nil
-	}
-	if s, err := ParseString(token); err == nil {
-		return &Param{V: s, T: TypeStr}, nil
+		return &Param{V: &Sexpr{
+			List:   []Param{{V: rf.form, T: TypeUnknown, Pos: &pos}, *item},
+			Quoted: quoted,
+		}, T: TypeList, Pos: &pos}, nil
 	}
-	// TODO
-	return &Param{V: Ident(token), T: TypeUnknown}, nil
+	return p.tokenParam(token), nil
 }
 
 func (p *Parser) nextSexpr(leftBrace string, quoted bool) (*Param, error) {
+	pos := p.pos
 	var list []Param
 	for {
 		token, err := p.nextToken()
 		if err == io.EOF {
-			return nil, UnexpectedEOF
+			return nil, &PosError{Pos: pos, Err: UnexpectedEOF}
 		}
 		if err != nil {
 			return nil, err
@@ -77,38 +105,37 @@ func (p *Parser) nextSexpr(leftBrace string, quoted bool) (*Param, error) {
 		if token == ")" {
 			break
 		}
-		if token == "(" || token == "'(" || token == "\\(" {
-			item, err := p.nextSexpr(token, quoted || token == "'(")
-			if err != nil {
-				return nil, err
-			}
-			list = append(list, *item)
-			continue
+		item, err := p.parseExpr(token, quoted)
+		if err != nil {
+			return nil, err
 		}
-		par := p.tokenParam(token)
-		list = append(list, *par)
+		list = append(list, *item)
 	}
 
 	return &Param{V: &Sexpr{
 		List:   list,
 		Quoted: quoted || leftBrace == "'(",
 		Lambda: leftBrace == "\\(",
-	}, T: TypeList}, nil
+	}, T: TypeList, Pos: &pos}, nil
 }
 
 func (p *Parser) tokenParam(token string) *Param {
+	pos := p.pos
 	if token == "'T" || token == "'F" || token == "true" || token == "false" {
 		v := token == "'T" || token == "true"
-		return &Param{V: Bool(v), T: TypeBool}
+		return &Param{V: Bool(v), T: TypeBool, Pos: &pos}
 	}
 	if n, ok := p.intParser.ParseInt(token); ok {
-		return &Param{V: n, T: TypeInt}
+		return &Param{V: n, T: TypeInt, Pos: &pos}
+	}
+	if f, ok := ParseFloat(token); ok {
+		return &Param{V: f, T: TypeFloat, Pos: &pos}
 	}
 	if s, err := ParseString(token); err == nil {
-		return &Param{V: s, T: TypeStr}
+		return &Param{V: s, T: TypeStr, Pos: &pos}
 	}
 	// TODO
-	return &Param{V: Ident(token), T: TypeUnknown}
+	return &Param{V: Ident(token), T: TypeUnknown, Pos: &pos}
 }
 
 func (p *Parser) nextToken() (string, error) {
@@ -119,6 +146,8 @@ func (p *Parser) nextToken() (string, error) {
 	}
 	token := p.tokens[0]
 	p.tokens = p.tokens[1:]
+	p.pos = p.positions[0]
+	p.positions = p.positions[1:]
 	return token, nil
 }
 
@@ -129,16 +158,31 @@ func (p *Parser) prepareTokens() error {
 		}
 		return io.EOF
 	}
-	line := strings.TrimSpace(p.scanner.Text())
+	p.line++
+	text := p.scanner.Text()
+	line := strings.TrimSpace(text)
 	if line == "" || line[0] == '#' || line[0] == ';' {
 		return p.prepareTokens()
 	}
+	// column offset of the trimmed line
+	offset := strings.Index(text, line) + 1
 
 	var token string
 	var tokens []string
+	var positions []Pos
+	// column where current token started
+	start := 0
+	emit := func(tok string, col int) {
+		tokens = append(tokens, tok)
+		positions = append(positions, Pos{File: p.file, Line: p.line, Col: col})
+	}
 	inQuotes := false
 	backslash := false
-	for _, r := range line {
+	for i, r := range line {
+		col := offset + i
+		if token == "" {
+			start = col
+		}
 		if backslash {
 			token += `\` + string(r)
 			backslash = false
@@ -149,7 +193,7 @@ func (p *Parser) prepareTokens() error {
 				token += string(r)
 				if r == '"' {
 					inQuotes = false
-					tokens = append(tokens, token)
+					emit(token, start)
 					token = ""
 				}
 			}
@@ -160,33 +204,43 @@ func (p *Parser) prepareTokens() error {
 			if inQuotes {
 				token += string(r)
 			} else if token != "" {
-				tokens = append(tokens, token)
+				emit(token, start)
 				token = ""
 			}
 		} else if r == '(' {
 			if token == "'" {
-				tokens = append(tokens, "'(")
+				emit("'(", start)
 			} else if token == "\\" {
-				tokens = append(tokens, `\(`)
+				emit(`\(`, start)
+			} else if isReaderPrefix(token) {
+				// `( ,( ,@( `'( etc.
+				emit(token+"(", start)
 			} else if token != "" {
-				tokens = append(tokens, token, "(")
+				emit(token, start)
+				emit("(", col)
 			} else {
-				tokens = append(tokens, "(")
+				emit("(", col)
 			}
 			token = ""
 		} else if r == ')' {
 			if token != "" {
-				tokens = append(tokens, token)
+				emit(token, start)
 				token = ""
 			}
-			tokens = append(tokens, ")")
+			emit(")", col)
 		} else {
 			token += string(r)
 		}
 	}
 	if token != "" {
-		tokens = append(tokens, token)
+		emit(token, start)
 	}
 	p.tokens = tokens
+	p.positions = positions
 	return nil
 }
+
+func isReaderPrefix(token string) bool {
+	token = strings.TrimRight(token, "'\\")
+	return token != "" && strings.Trim(token, "`,@") == ""
+}
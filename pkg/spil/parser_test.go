@@ -1,4 +1,4 @@
-package main
+package spil
 
 import (
 	"io"
@@ -25,6 +25,8 @@ func TestNextToken(t *testing.T) {
 		{"(hello)\ntrue\n#vim ft=lisp", []string{"(", "hello", ")", "true"}},
 		{`\(foo bar)`, []string{`\(`, "foo", "bar", ")"}},
 		{`"(set n (get-int) :int)"`, []string{`"(set n (get-int) :int)"`}},
+		{"`(a ,b ,@c ,(d))", []string{"`(", "a", ",b", ",@c", ",(", "d", ")", ")"}},
+		{"`'(a)", []string{"`'(", "a", ")"}},
 	}
 
 	for _, test := range testdata {
@@ -64,15 +66,13 @@ func TestNextExpr(t *testing.T) {
 		result string
 	}{
 		{`'((1 2 3) (4 5 6))`, `{S': {S': {Int64: 1} {Int64: 2} {Int64: 3}} {S': {Int64: 4} {Int64: 5} {Int64: 6}}}`},
+		{"`(a ,b ,@c)", `{S: {Ident: quasiquote} {S: {Ident: a} {S: {Ident: unquote} {Ident: b}} {S: {Ident: unquote-splicing} {Ident: c}}}}`},
+		{"`'(,a)", `{S: {Ident: quasiquote} {S': {S': {Ident: unquote} {Ident: a}}}}`},
 	}
 	for _, test := range testdata {
 		name := test.input
 		t.Run(name, func(t *testing.T) {
-			var intMaker IntMaker = Int64Maker{}
-			if bigint {
-				intMaker = BigIntMaker{}
-			}
-			p := NewParser(strings.NewReader(test.input), IntParserFn(intMaker.ParseInt))
+			p := NewParser(strings.NewReader(test.input), IntParserFn(Int64Maker{}.ParseInt))
 			res, err := p.NextExpr()
 			if err != nil {
 				t.Fatal(err)
@@ -0,0 +1,158 @@
+package spil
+
+import (
+	"fmt"
+	"io"
+	"math/big"
+)
+
+// Rational is an exact fraction produced by division in (use rational) mode.
+type Rational struct {
+	value *big.Rat
+	// big is set for rationals produced from big integers (-big mode):
+	// whole-number rationals are hashed as integers of the same kind.
+	big bool
+}
+
+var _ Expr = (*Rational)(nil)
+
+func NewRational(a, b int64) *Rational {
+	return &Rational{value: big.NewRat(a, b)}
+}
+
+func (r *Rational) String() string {
+	return fmt.Sprintf("{Rational: %v}", r.value.RatString())
+}
+
+// Hash does not depend on internal representation because big.Rat is always normalized.
+// Whole numbers are hashed as integers because they are equal to them (see Equal).
+func (r *Rational) Hash() (string, error) {
+	if r.value.IsInt() {
+		return r.integer().Hash()
+	}
+	return r.String(), nil
+}
+
+// integer converts whole-number rational into integer of the same kind as integers it was produced from.
+func (r *Rational) integer() Int {
+	n := new(big.Int).Set(r.value.Num())
+	if r.big {
+		return &BigInt{value: n}
+	}
+	if n.IsInt64() {
+		return Int64(n.Int64())
+	}
+	return &BigInt{value: n, auto: true}
+}
+
+// isBigMode returns true for values produced in -big mode.
+func isBigMode(e Expr) bool {
+	switch a := e.(type) {
+	case *BigInt:
+		return !a.auto
+	case *Rational:
+		return a.big
+	}
+	return false
+}
+
+func (r *Rational) Print(w io.Writer) {
+	io.WriteString(w, r.value.RatString())
+}
+
+func (r *Rational) Type() Type {
+	return TypeRational
+}
+
+// toRat converts exact numeric value (integer or rational) into big.Rat.
+func toRat(e Expr) (*big.Rat, bool) {
+	switch a := e.(type) {
+	case *Rational:
+		return a.value, true
+	case *BigInt:
+		return new(big.Rat).SetInt(a.value), true
+	case Int:
+		return new(big.Rat).SetInt64(a.Int64()), true
+	}
+	return nil, false
+}
+
+func hasRationals(args []Param) bool {
+	for _, arg := range args {
+		if _, ok := arg.V.(*Rational); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// ratOp folds arguments (integers are promoted to rationals) with the specified operation.
+func ratOp(name string, args []Param, op func(a, b *big.Rat) (*big.Rat, error)) (*Param, error) {
+	var result *big.Rat
+	bigMode := false
+	for i, arg := range args {
+		bigMode = bigMode || isBigMode(arg.V)
+		a, ok := toRat(arg.V)
+		if !ok {
+			return nil, fmt.Errorf("%v: expected exact numeric argument in position %v, found %v", name, i, arg)
+		}
+		if i == 0 {
+			result = new(big.Rat).Set(a)
+			continue
+		}
+		var err error
+		if result, err = op(result, a); err != nil {
+			return nil, err
+		}
+	}
+	return &Param{V: &Rational{value: result, big: bigMode}, T: TypeRational}, nil
+}
+
+// FRationalDiv is the division function in (use rational) mode: the result of division of integers is exact.
+func FRationalDiv(args []Param) (*Param, error) {
+	if hasFloats(args) {
+		return floatOp("FDiv", args, func(a, b float64) float64 { return a / b })
+	}
+	return ratOp("FDiv", args, func(a, b *big.Rat) (*big.Rat, error) {
+		if b.Sign() == 0 {
+			return nil, fmt.Errorf("FDiv: division by zero")
+		}
+		return a.Quo(a, b), nil
+	})
+}
+
+// equalRat compares exact numeric values (integer 2 is equal to rational 4/2).
+func equalRat(a, b Expr) bool {
+	ra, aok := toRat(a)
+	rb, bok := toRat(b)
+	return aok && bok && ra.Cmp(rb) == 0
+}
+
+func FRationalLess(args []Param) (*Param, error) {
+	if len(args) != 2 {
+		return nil, fmt.Errorf("FRationalLess: expected 2 arguments, found %v", args)
+	}
+	a, ok := toRat(args[0].V)
+	if !ok {
+		return nil, fmt.Errorf("FRationalLess: first argument should be rational, found %v", args[0])
+	}
+	b, ok := toRat(args[1].V)
+	if !ok {
+		return nil, fmt.Errorf("FRationalLess: second argument should be rational, found %v", args[1])
+	}
+	return &Param{V: Bool(a.Cmp(b) < 0), T: TypeBool}, nil
+}
+
+func (in *Interpret) useRational() {
+	in.funcs["/"] = TypedEvalerFunc("/", FRationalDiv, in.RationalDivArgs, TypeFloat)
+}
+
+// RationalDivArgs checks arguments of division in (use rational) mode.
+// Result is :float if any of arguments is :float and :rational otherwise.
+func (in *Interpret) RationalDivArgs(params []Param) (Type, error) {
+	t, err := in.Numbers(params)
+	if err != nil || t == TypeFloat {
+		return t, err
+	}
+	return TypeRational, nil
+}
@@ -0,0 +1,76 @@
+package spil
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+)
+
+func TestRationalPrint(t *testing.T) {
+	tests := []struct {
+		r   *Rational
+		exp string
+	}{
+		{NewRational(1, 3), "1/3"},
+		{NewRational(6, 4), "3/2"},
+		{NewRational(-4, 2), "-2"},
+		{NewRational(0, 5), "0"},
+	}
+	for _, test := range tests {
+		t.Run(test.exp, func(t *testing.T) {
+			b := &strings.Builder{}
+			test.r.Print(b)
+			if act := b.String(); act != test.exp {
+				t.Errorf("Incorrect rational representation: expected %q, actual %q", test.exp, act)
+			}
+		})
+	}
+}
+
+func TestRationalHash(t *testing.T) {
+	h1, _ := NewRational(1, 3).Hash()
+	h2, _ := NewRational(3, 9).Hash()
+	if h1 != h2 {
+		t.Errorf("Hashes of equal rationals differ: %q, %q", h1, h2)
+	}
+	h3, _ := NewRational(1, 4).Hash()
+	if h1 == h3 {
+		t.Errorf("Hashes of different rationals are equal: %q", h1)
+	}
+}
+
+func TestRationalEqual(t *testing.T) {
+	tests := []struct {
+		a, b Expr
+		exp  bool
+	}{
+		{NewRational(4, 2), Int64(2), true},
+		{Int64(2), NewRational(4, 2), true},
+		{NewRational(4, 2), BigIntMaker{}.MakeInt(2), true},
+		{NewRational(1, 2), Int64(0), false},
+		{NewRational(1, 2), NewRational(2, 4), true},
+		{NewRational(1, 2), Str("1/2"), false},
+	}
+	for _, test := range tests {
+		if act := Equal(test.a, test.b); act != test.exp {
+			t.Errorf("Equal(%v, %v) failed: expected %v, actual %v", test.a, test.b, test.exp, act)
+		}
+	}
+}
+
+func TestRationalHashWholeNumber(t *testing.T) {
+	tests := []struct {
+		r Expr
+		i Expr
+	}{
+		{NewRational(4, 2), Int64(2)},
+		{&Rational{value: big.NewRat(4, 2), big: true}, BigIntMaker{}.MakeInt(2)},
+	}
+	for _, test := range tests {
+		hr, _ := test.r.Hash()
+		hi, _ := test.i.Hash()
+		if hr != hi {
+			t.Errorf("Hashes of equal rational and integer differ: %q, %q", hr, hi)
+		}
+	}
+}
@@ -0,0 +1,284 @@
+package spil
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// recordDef describes record type defined with (defrecord :name (field1:type1 field2:type2 ...))
+// or variant of algebraic data type defined with defunion.
+type recordDef struct {
+	name   Type
+	fields []Arg
+	// algebraic data type which this variant belongs to (empty for records)
+	union Type
+}
+
+// Type of values constructed by the record definition.
+func (d *recordDef) Type() Type {
+	if d.union != "" {
+		return d.union
+	}
+	return d.name
+}
+
+// Record is an immutable value of record type.
+type Record struct {
+	def    *recordDef
+	values []Param
+}
+
+var _ Expr = (*Record)(nil)
+
+func (r *Record) String() string {
+	b := &strings.Builder{}
+	fmt.Fprintf(b, "{Record %v:", r.def.name)
+	for i, f := range r.def.fields {
+		fmt.Fprintf(b, " %v => %v", f.Name, r.values[i].V)
+	}
+	b.WriteString("}")
+	return b.String()
+}
+
+func (r *Record) Hash() (string, error) {
+	b := &strings.Builder{}
+	fmt.Fprintf(b, "{Record %v:", r.def.name)
+	for _, v := range r.values {
+		h, err := v.V.Hash()
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(b, " %v", h)
+	}
+	b.WriteString("}")
+	return b.String(), nil
+}
+
+func (r *Record) Print(w io.Writer) {
+	if r.def.union != "" {
+		// variants are printed as constructor calls: (ok 5)
+		io.WriteString(w, "(")
+		io.WriteString(w, string(r.def.name))
+		for _, v := range r.values {
+			io.WriteString(w, " ")
+			v.V.Print(w)
+		}
+		io.WriteString(w, ")")
+		return
+	}
+	io.WriteString(w, string(r.def.name))
+	io.WriteString(w, "{")
+	for i, f := range r.def.fields {
+		if i > 0 {
+			io.WriteString(w, ", ")
+		}
+		io.WriteString(w, f.Name)
+		io.WriteString(w, " ")
+		r.values[i].V.Print(w)
+	}
+	io.WriteString(w, "}")
+}
+
+func (r *Record) Type() Type {
+	return r.def.Type()
+}
+
+// (defrecord :name (field1:type1 field2:type2 ...))
+// defines type :name and the following functions:
+// (make-name value1 value2 ...) - constructor;
+// (name-field1 record) - field accessors;
+// (name-with-field1 record value) - returns copy of record with updated field.
+func (in *Interpret) defineRecord(args []Param) error {
+	if len(args) != 2 {
+		return fmt.Errorf("'defrecord' expected two arguments, found: %v", args)
+	}
+	id, ok := args[0].V.(Ident)
+	if !ok {
+		return fmt.Errorf("defrecord expects first argument to be new type, found: %v", args[0])
+	}
+	name, ok := ParseType(string(id))
+	if !ok || len(name.Arguments()) > 0 {
+		return fmt.Errorf("defrecord expects first argument to be new type, found: %v", args[0])
+	}
+	if _, ok := in.types[name]; ok {
+		return fmt.Errorf("Cannot redefine type %v", name)
+	}
+	fieldsList, ok := args[1].V.(*Sexpr)
+	if !ok {
+		return fmt.Errorf("defrecord expects second argument to be list of fields, found: %v", args[1])
+	}
+	def := &recordDef{name: name}
+	// record type should be known to be used in types of its fields
+	in.types[name] = TypeAny
+	fields, err := in.parseFields("defrecord", name, fieldsList.List)
+	if err != nil {
+		delete(in.types, name)
+		return err
+	}
+	def.fields = fields
+
+	funcs := map[string]Evaler{}
+	cons := "make-" + string(name)
+	funcs[cons] = TypedEvalerFunc(cons, func(args []Param) (*Param, error) {
+		return in.FMakeRecord(def, args)
+	}, func(params []Param) (Type, error) {
+		return in.MakeRecordArgs(def, params)
+	}, name)
+	for i, f := range def.fields {
+		i, f := i, f
+		getter := string(name) + "-" + f.Name
+		funcs[getter] = TypedEvalerFunc(getter, func(args []Param) (*Param, error) {
+			return in.FRecordGet(def, i, args)
+		}, func(params []Param) (Type, error) {
+			return in.RecordGetArgs(def, i, params)
+		}, f.T)
+		setter := string(name) + "-with-" + f.Name
+		funcs[setter] = TypedEvalerFunc(setter, func(args []Param) (*Param, error) {
+			return in.FRecordWith(def, i, args)
+		}, func(params []Param) (Type, error) {
+			return in.RecordWithArgs(def, i, params)
+		}, name)
+	}
+	if err := in.addFuncs(funcs); err != nil {
+		delete(in.types, name)
+		return fmt.Errorf("Cannot define record %v: %w", name, err)
+	}
+	return nil
+}
+
+// addFuncs registers generated native functions if none of them is already defined.
+func (in *Interpret) addFuncs(funcs map[string]Evaler) error {
+	for fname := range funcs {
+		if _, ok := in.funcs[fname]; ok {
+			return fmt.Errorf("function %v already exists", fname)
+		}
+	}
+	for fname, f := range funcs {
+		in.funcs[fname] = f
+	}
+	return nil
+}
+
+// parseFields parses list of fields (field1:type1 field2:type2 ...) of record or variant.
+func (in *Interpret) parseFields(form string, name Type, list []Param) ([]Arg, error) {
+	args, err := parseArgs(list)
+	if err != nil {
+		return nil, fmt.Errorf("%v %v: %w", form, name, err)
+	}
+	var fields []Arg
+	for i, f := range args {
+		if f.Name == "" || f.V != nil || f.Pattern != nil {
+			return nil, fmt.Errorf("%v %v: expected field name, found %v", form, name, list[i])
+		}
+		for _, prev := range fields {
+			if prev.Name == f.Name {
+				return nil, fmt.Errorf("%v %v: duplicate field %v", form, name, f.Name)
+			}
+		}
+		if f.T == TypeUnknown {
+			f.T = TypeAny
+		}
+		if f.T, err = in.parseType(f.T.String()); err != nil {
+			return nil, fmt.Errorf("%v %v: %w", form, name, err)
+		}
+		if in.IsGeneric(f.T) {
+			return nil, fmt.Errorf("%v %v: generic field types are not supported: %v", form, name, f.T)
+		}
+		fields = append(fields, f)
+	}
+	return fields, nil
+}
+
+// (make-name value1 value2 ...)
+func (in *Interpret) FMakeRecord(def *recordDef, args []Param) (*Param, error) {
+	if _, err := in.MakeRecordArgs(def, args); err != nil {
+		return nil, fmt.Errorf("FMakeRecord: %w", err)
+	}
+	r := &Record{def: def, values: make([]Param, len(args))}
+	for i := range args {
+		r.values[i] = *in.recordValue(def, i, args[i])
+	}
+	return &Param{V: r, T: def.Type()}, nil
+}
+
+// (name-field record)
+func (in *Interpret) FRecordGet(def *recordDef, field int, args []Param) (*Param, error) {
+	if len(args) != 1 {
+		return nil, fmt.Errorf("FRecordGet: expected 1 argument, found %v", args)
+	}
+	r, ok := args[0].V.(*Record)
+	if !ok || r.def != def {
+		return nil, fmt.Errorf("FRecordGet: expected argument to be %v, found %v", def.name, args[0])
+	}
+	v := r.values[field]
+	return &v, nil
+}
+
+// (name-with-field record value)
+func (in *Interpret) FRecordWith(def *recordDef, field int, args []Param) (*Param, error) {
+	if len(args) != 2 {
+		return nil, fmt.Errorf("FRecordWith: expected 2 arguments, found %v", args)
+	}
+	r, ok := args[0].V.(*Record)
+	if !ok || r.def != def {
+		return nil, fmt.Errorf("FRecordWith: expected first argument to be %v, found %v", def.name, args[0])
+	}
+	if err := in.expectType(def.fields[field].Name, args[1], def.fields[field].T); err != nil {
+		return nil, fmt.Errorf("FRecordWith: %w", err)
+	}
+	res := &Record{def: def, values: append([]Param(nil), r.values...)}
+	res.values[field] = *in.recordValue(def, field, args[1])
+	return &Param{V: res, T: args[0].T}, nil
+}
+
+// recordValue converts value to the type of the field.
+func (in *Interpret) recordValue(def *recordDef, field int, p Param) *Param {
+	t := def.fields[field].T
+	v := in.promote(&p, t)
+	if v.T == TypeUnknown {
+		v = &Param{V: v.V, T: t, Pos: v.Pos}
+	}
+	return v
+}
+
+// Typers
+
+func (in *Interpret) MakeRecordArgs(def *recordDef, params []Param) (Type, error) {
+	if len(params) != len(def.fields) {
+		return TypeUnknown, fmt.Errorf("expected %v arguments, found %v", len(def.fields), params)
+	}
+	for i, f := range def.fields {
+		if err := in.expectType(f.Name, params[i], f.T); err != nil {
+			return TypeUnknown, err
+		}
+	}
+	return def.Type(), nil
+}
+
+func (in *Interpret) RecordGetArgs(def *recordDef, field int, params []Param) (Type, error) {
+	if len(params) != 1 {
+		return TypeUnknown, fmt.Errorf("expected 1 argument, found %v", params)
+	}
+	if err := in.expectType("argument", params[0], def.name); err != nil {
+		return TypeUnknown, err
+	}
+	return def.fields[field].T, nil
+}
+
+func (in *Interpret) RecordWithArgs(def *recordDef, field int, params []Param) (Type, error) {
+	if len(params) != 2 {
+		return TypeUnknown, fmt.Errorf("expected 2 arguments, found %v", params)
+	}
+	if err := in.expectType("first argument", params[0], def.name); err != nil {
+		return TypeUnknown, err
+	}
+	f := def.fields[field]
+	if err := in.expectType(f.Name, params[1], f.T); err != nil {
+		return TypeUnknown, err
+	}
+	if params[0].T == TypeUnknown {
+		return def.name, nil
+	}
+	return params[0].T, nil
+}
@@ -0,0 +1,57 @@
+package spil
+
+import (
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+func TestDefineRecordErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		exp   string
+	}{
+		{"redefine type", "(defrecord :int (x:int))", "Cannot redefine type :int"},
+		{"duplicate field", "(defrecord :point (x:int x:int))", "duplicate field x"},
+		{"unknown field type", "(defrecord :point (x:int y:foo))", "Cannot parse type :foo: not defined"},
+		{"function exists", "(def point-x (p) p)\n(defrecord :point (x:int y:int))", "function point-x already exists"},
+		{"wrong field type", "(defrecord :point (x:int y:int))\n(print (make-point 1 \"2\"))", "expected y to be :int, found :str"},
+		{"wrong accessor argument", "(defrecord :foo (x:int))\n(defrecord :bar (x:int))\n(print (foo-x (make-bar 1)))", "expected argument to be :foo, found :bar"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			in := NewInterpreter(ioutil.Discard, getTestLibraryDir())
+			err := in.Parse("test.lisp", strings.NewReader(test.input))
+			if err == nil {
+				if errs := in.Check(); len(errs) > 0 {
+					err = errs[0]
+				}
+			}
+			if err == nil {
+				t.Fatalf("Error expected")
+			}
+			if !strings.Contains(err.Error(), test.exp) {
+				t.Errorf("Incorrect error: expected %q, actual %q", test.exp, err.Error())
+			}
+		})
+	}
+}
+
+func TestRecordEqual(t *testing.T) {
+	point := &recordDef{name: "point", fields: []Arg{{Name: "x", T: TypeInt}, {Name: "y", T: TypeInt}}}
+	other := &recordDef{name: "other", fields: point.fields}
+	p1 := &Record{def: point, values: []Param{intParam(1), intParam(2)}}
+	p2 := &Record{def: point, values: []Param{intParam(1), intParam(2)}}
+	p3 := &Record{def: point, values: []Param{intParam(2), intParam(1)}}
+	o := &Record{def: other, values: []Param{intParam(1), intParam(2)}}
+	if !Equal(p1, p2) {
+		t.Errorf("Equal records are not equal: %v, %v", p1, p2)
+	}
+	if Equal(p1, p3) {
+		t.Errorf("Different records are equal: %v, %v", p1, p3)
+	}
+	if Equal(p1, o) {
+		t.Errorf("Records of different types are equal: %v, %v", p1, o)
+	}
+}
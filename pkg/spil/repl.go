@@ -0,0 +1,279 @@
+package spil
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+const replFile = "__repl__"
+
+// Repl is an interactive read-eval-print loop over the interpreter.
+// Every top-level form is evaluated as soon as it is read
+// in the context of the __main__ function, so variables are kept between inputs.
+type Repl struct {
+	in     *Interpret
+	input  *bufio.Scanner
+	output io.Writer
+
+	// runtime of the __main__ function
+	run *FuncRuntime
+	// types of variables defined in the main function
+	vars map[string]Type
+}
+
+func NewRepl(in *Interpret, r io.Reader, w io.Writer) *Repl {
+	return &Repl{
+		in:     in,
+		input:  bufio.NewScanner(r),
+		output: w,
+		vars:   mainVars(),
+	}
+}
+
+// Run reads and evaluates forms until the end of input.
+func (r *Repl) Run() error {
+	if err := r.in.Parse(replFile, strings.NewReader("")); err != nil {
+		return err
+	}
+	r.in.main.capturedVars["__stdin"] = &Param{V: QEmpty, T: TypeStr}
+	r.run = NewFuncRuntime(r.in.main)
+	if _, _, _, _, err := r.run.bind(nil); err != nil {
+		return err
+	}
+	for {
+		text, err := r.readForm()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := r.eval(text); err != nil {
+			fmt.Fprintf(r.output, "%v\n", err)
+			var rerr *RuntimeError
+			if errors.As(err, &rerr) {
+				rerr.PrintStack(r.output)
+			}
+		}
+	}
+}
+
+// readForm reads lines until they contain complete top-level forms.
+func (r *Repl) readForm() (string, error) {
+	prompt := "> "
+	text := ""
+	for {
+		fmt.Fprint(r.output, prompt)
+		if !r.input.Scan() {
+			if err := r.input.Err(); err != nil {
+				return "", err
+			}
+			fmt.Fprintln(r.output)
+			if strings.TrimSpace(text) == "" {
+				return "", io.EOF
+			}
+			return text, nil
+		}
+		text += r.input.Text() + "\n"
+		if complete(text) {
+			return text, nil
+		}
+		prompt = ". "
+	}
+}
+
+// complete returns false if text contains unbalanced parentheses.
+func complete(text string) bool {
+	parser := NewParser(strings.NewReader(text), Int64Maker{})
+	for {
+		_, err := parser.NextExpr()
+		if err == io.EOF {
+			return true
+		}
+		if errors.Is(err, UnexpectedEOF) {
+			return false
+		}
+		if err != nil {
+			// let the interpreter report the error
+			return true
+		}
+	}
+}
+
+func (r *Repl) eval(text string) error {
+	in := r.in
+	// remember state to rollback definitions in case of errors
+	state := saveState(in)
+	rollback := func() {
+		state.restore(in)
+	}
+
+	if err := in.parse(replFile, strings.NewReader(text)); err != nil {
+		rollback()
+		return err
+	}
+
+	// check new function implementations
+	var errs []error
+	for _, fn := range in.funcs {
+		fi, ok := fn.(*FuncInterpret)
+		if !ok {
+			continue
+		}
+		for _, impl := range fi.bodies[state.bodies[fi]:] {
+			errs = append(errs, in.checkImpl(fi, impl)...)
+		}
+	}
+	// check new statements
+	stmts := in.mainBody[state.mainLen:]
+	vars := make(map[string]Type, len(r.vars))
+	for k, v := range r.vars {
+		vars[k] = v
+	}
+	for _, stmt := range stmts {
+		var err error
+		if isSetStatement(stmt) {
+			err = in.statementType("__main__", stmt, vars)
+		} else {
+			_, err = in.exprType("__main__", stmt, vars)
+		}
+		if err != nil {
+			errs = append(errs, err)
+			break
+		}
+	}
+	if len(errs) > 0 {
+		rollback()
+		for _, err := range errs[:len(errs)-1] {
+			fmt.Fprintf(r.output, "%v\n", err)
+		}
+		return errs[len(errs)-1]
+	}
+
+	runVars := make(map[string]Param, len(r.run.vars))
+	for k, v := range r.run.vars {
+		runVars[k] = v
+	}
+	for _, stmt := range stmts {
+		res, err := r.run.evalParameter(&stmt)
+		if err != nil {
+			// variables set by the failed input are forgotten
+			r.run.vars = runVars
+			return err
+		}
+		if isSetStatement(stmt) {
+			continue
+		}
+		res.V.Print(r.output)
+		fmt.Fprintf(r.output, " %v\n", res.T)
+	}
+	r.vars = vars
+	return nil
+}
+
+// replState is a snapshot of definitions of the interpreter
+// (functions, types, contracts, macros and loaded modules).
+type replState struct {
+	mainLen       int
+	funcs         map[string]Evaler
+	bodies        map[*FuncInterpret]int
+	returnTypes   map[*FuncInterpret]Type
+	macros        map[string]*FuncInterpret
+	macroBodies   map[*FuncInterpret]int
+	types         map[Type]Type
+	contracts     map[Type]struct{}
+	contractFuncs map[Type][]contractFunc
+	inferred      map[string]Type
+	unions        map[Type][]*recordDef
+	variants      map[Type]*recordDef
+	loadedModules map[string]bool
+	intMaker      IntMaker
+	strictTypes   bool
+}
+
+func saveState(in *Interpret) *replState {
+	s := &replState{
+		mainLen:       len(in.mainBody),
+		funcs:         make(map[string]Evaler, len(in.funcs)),
+		bodies:        map[*FuncInterpret]int{},
+		returnTypes:   map[*FuncInterpret]Type{},
+		macros:        make(map[string]*FuncInterpret, len(in.macros)),
+		macroBodies:   map[*FuncInterpret]int{},
+		types:         make(map[Type]Type, len(in.types)),
+		contracts:     make(map[Type]struct{}, len(in.contracts)),
+		contractFuncs: make(map[Type][]contractFunc, len(in.contractFuncs)),
+		inferred:      make(map[string]Type, len(in.inferred)),
+		unions:        make(map[Type][]*recordDef, len(in.unions)),
+		variants:      make(map[Type]*recordDef, len(in.variants)),
+		loadedModules: make(map[string]bool, len(in.loadedModules)),
+		intMaker:      in.intMaker,
+		strictTypes:   in.strictTypes,
+	}
+	for name, fn := range in.funcs {
+		s.funcs[name] = fn
+		if fi, ok := fn.(*FuncInterpret); ok {
+			s.bodies[fi] = len(fi.bodies)
+			s.returnTypes[fi] = fi.returnType
+		}
+	}
+	for name, mi := range in.macros {
+		s.macros[name] = mi
+		s.macroBodies[mi] = len(mi.bodies)
+	}
+	for k, v := range in.types {
+		s.types[k] = v
+	}
+	for k, v := range in.contracts {
+		s.contracts[k] = v
+	}
+	for k, v := range in.contractFuncs {
+		s.contractFuncs[k] = v
+	}
+	for k, v := range in.inferred {
+		s.inferred[k] = v
+	}
+	for k, v := range in.unions {
+		s.unions[k] = v
+	}
+	for k, v := range in.variants {
+		s.variants[k] = v
+	}
+	for k, v := range in.loadedModules {
+		s.loadedModules[k] = v
+	}
+	return s
+}
+
+func (s *replState) restore(in *Interpret) {
+	in.mainBody = in.mainBody[:s.mainLen]
+	for fi, n := range s.bodies {
+		fi.bodies = fi.bodies[:n]
+		fi.returnType = s.returnTypes[fi]
+	}
+	for mi, n := range s.macroBodies {
+		mi.bodies = mi.bodies[:n]
+	}
+	in.funcs = s.funcs
+	in.macros = s.macros
+	in.types = s.types
+	in.contracts = s.contracts
+	in.contractFuncs = s.contractFuncs
+	in.inferred = s.inferred
+	in.unions = s.unions
+	in.variants = s.variants
+	in.loadedModules = s.loadedModules
+	in.intMaker = s.intMaker
+	in.strictTypes = s.strictTypes
+}
+
+func isSetStatement(stmt Param) bool {
+	se, ok := stmt.V.(*Sexpr)
+	if !ok || se.Quoted || se.Empty() {
+		return false
+	}
+	name, ok := se.List[0].V.(Ident)
+	return ok && (name == "set" || name == "set'")
+}
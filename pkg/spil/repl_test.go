@@ -0,0 +1,59 @@
+package spil
+
+import (
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+func TestRepl(t *testing.T) {
+	input := `(+ 1 2)
+(set x 10)
+(def sq (n:int) :int
+  (* n n))
+(sq x)
+(sq "a")
+(def sq (s:str) :int (native.length s))
+(sq "a")
+"str"
+`
+	exp := `> 3 :int
+> > . > 100 :int
+> __repl__:1:1: __main__: sq: no matching function implementation found for [{:str {Str: "a"}}]
+> > 1 :int
+> str :str
+> 
+`
+	out := &strings.Builder{}
+	in := NewInterpreter(ioutil.Discard, getTestLibraryDir())
+	if err := NewRepl(in, strings.NewReader(input), out).Run(); err != nil {
+		t.Fatalf("Repl.Run() failed: %v", err)
+	}
+	if act := out.String(); act != exp {
+		t.Errorf("Incorrect REPL output:\nexpected %q,\n  actual %q", exp, act)
+	}
+}
+
+func TestReplRollback(t *testing.T) {
+	input := `(deftype :meters :int) (print undefined-var)
+(deftype :meters :int)
+(set y (+ 1 (error "fail")))
+y
+(set y 2)
+(+ y 1)
+`
+	exp := `> __repl__:1:31: Undefined variable: undefined-var
+> > __repl__:1:13: fail
+> __repl__:1:1: Undefined variable: y
+> > 3 :int
+> 
+`
+	out := &strings.Builder{}
+	in := NewInterpreter(ioutil.Discard, getTestLibraryDir())
+	if err := NewRepl(in, strings.NewReader(input), out).Run(); err != nil {
+		t.Fatalf("Repl.Run() failed: %v", err)
+	}
+	if act := out.String(); act != exp {
+		t.Errorf("Incorrect REPL output:\nexpected %q,\n  actual %q", exp, act)
+	}
+}
@@ -0,0 +1,398 @@
+package spil
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Set is an immutable set of values. Values are compared by their hashes.
+type Set struct {
+	h *hamt
+}
+
+var _ Expr = (*Set)(nil)
+var _ Lenghter = (*Set)(nil)
+
+var EmptySet = &Set{emptyHamt}
+
+func NewSet(items ...Param) (*Set, error) {
+	s := EmptySet
+	for _, item := range items {
+		var err error
+		if s, err = s.Insert(item); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+func (s *Set) Insert(p Param) (*Set, error) {
+	e, err := newHamtEntry(p, Param{})
+	if err != nil {
+		return nil, err
+	}
+	return &Set{s.h.put(e)}, nil
+}
+
+func (s *Set) Remove(p Param) (*Set, error) {
+	h, err := s.h.remove(p)
+	if err != nil {
+		return nil, err
+	}
+	return &Set{h}, nil
+}
+
+func (s *Set) Contains(p Param) (bool, error) {
+	e, err := s.h.get(p)
+	return e != nil, err
+}
+
+func (s *Set) Union(o *Set) (*Set, error) {
+	if s.Length() < o.Length() {
+		s, o = o, s
+	}
+	res := s
+	err := o.each(func(p *Param) (err error) {
+		res, err = res.Insert(*p)
+		return
+	})
+	return res, err
+}
+
+func (s *Set) Intersection(o *Set) (*Set, error) {
+	res := EmptySet
+	err := s.each(func(p *Param) error {
+		ok, err := o.Contains(*p)
+		if err != nil || !ok {
+			return err
+		}
+		res, err = res.Insert(*p)
+		return err
+	})
+	return res, err
+}
+
+func (s *Set) Difference(o *Set) (*Set, error) {
+	res := s
+	err := o.each(func(p *Param) (err error) {
+		res, err = res.Remove(*p)
+		return
+	})
+	return res, err
+}
+
+func (s *Set) Length() int {
+	return s.h.size
+}
+
+func (s *Set) each(fn func(p *Param) error) error {
+	return s.h.each(func(e *hamtEntry) error {
+		return fn(&e.key)
+	})
+}
+
+func (s *Set) String() string {
+	b := &strings.Builder{}
+	b.WriteString("{Set:")
+	s.each(func(p *Param) error {
+		fmt.Fprintf(b, " %v", p.V)
+		return nil
+	})
+	b.WriteString("}")
+	return b.String()
+}
+
+// Hash of set does not depend on the order in which elements were added.
+func (s *Set) Hash() (string, error) {
+	b := &strings.Builder{}
+	b.WriteString("{Set:")
+	s.h.each(func(e *hamtEntry) error {
+		fmt.Fprintf(b, " %v", e.hash)
+		return nil
+	})
+	b.WriteString("}")
+	return b.String(), nil
+}
+
+func (s *Set) Print(w io.Writer) {
+	io.WriteString(w, "#{")
+	first := true
+	s.each(func(p *Param) error {
+		if !first {
+			io.WriteString(w, " ")
+		}
+		first = false
+		p.V.Print(w)
+		return nil
+	})
+	io.WriteString(w, "}")
+}
+
+func (s *Set) Type() Type {
+	return "set[any]"
+}
+
+// Native functions
+
+// (set-of item1 item2 ...)
+func (in *Interpret) FSetOf(args []Param) (*Param, error) {
+	t, err := in.SetOfArgs(args)
+	if err != nil {
+		return nil, fmt.Errorf("FSetOf: %w", err)
+	}
+	return in.makeSet("FSetOf", args, t)
+}
+
+// (list->set list)
+func (in *Interpret) FListToSet(args []Param) (*Param, error) {
+	if len(args) != 1 {
+		return nil, fmt.Errorf("FListToSet: expected 1 argument, found %v", args)
+	}
+	l, ok := args[0].V.(List)
+	if !ok {
+		return nil, fmt.Errorf("FListToSet: expected argument to be List, found %v", args[0])
+	}
+	t, err := in.ListToSetArgs(args)
+	if err != nil {
+		return nil, fmt.Errorf("FListToSet: %w", err)
+	}
+	var items []Param
+	for !l.Empty() {
+		h, err := l.Head()
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, *h)
+		if l, err = l.Tail(); err != nil {
+			return nil, err
+		}
+	}
+	return in.makeSet("FListToSet", items, t)
+}
+
+func (in *Interpret) makeSet(name string, items []Param, t Type) (*Param, error) {
+	elem, _ := in.setElem(t)
+	s := EmptySet
+	for i := range items {
+		var err error
+		if s, err = s.Insert(*in.promote(&items[i], elem)); err != nil {
+			return nil, fmt.Errorf("%v: %w", name, err)
+		}
+	}
+	return &Param{V: s, T: t}, nil
+}
+
+// (set-insert set value)
+func (in *Interpret) FSetInsert(args []Param) (*Param, error) {
+	if len(args) != 2 {
+		return nil, fmt.Errorf("FSetInsert: expected 2 arguments, found %v", args)
+	}
+	s, ok := args[0].V.(*Set)
+	if !ok {
+		return nil, fmt.Errorf("FSetInsert: expected first argument to be Set, found %v", args[0])
+	}
+	elem, _ := in.setElem(args[0].T)
+	res, err := s.Insert(*in.promote(&args[1], elem))
+	if err != nil {
+		return nil, fmt.Errorf("FSetInsert: %w", err)
+	}
+	return &Param{V: res, T: args[0].T}, nil
+}
+
+// (set-remove set value)
+func (in *Interpret) FSetRemove(args []Param) (*Param, error) {
+	if len(args) != 2 {
+		return nil, fmt.Errorf("FSetRemove: expected 2 arguments, found %v", args)
+	}
+	s, ok := args[0].V.(*Set)
+	if !ok {
+		return nil, fmt.Errorf("FSetRemove: expected first argument to be Set, found %v", args[0])
+	}
+	res, err := s.Remove(args[1])
+	if err != nil {
+		return nil, fmt.Errorf("FSetRemove: %w", err)
+	}
+	return &Param{V: res, T: args[0].T}, nil
+}
+
+// (set-contains set value)
+func (in *Interpret) FSetContains(args []Param) (*Param, error) {
+	if len(args) != 2 {
+		return nil, fmt.Errorf("FSetContains: expected 2 arguments, found %v", args)
+	}
+	s, ok := args[0].V.(*Set)
+	if !ok {
+		return nil, fmt.Errorf("FSetContains: expected first argument to be Set, found %v", args[0])
+	}
+	res, err := s.Contains(args[1])
+	if err != nil {
+		return nil, fmt.Errorf("FSetContains: %w", err)
+	}
+	return &Param{V: Bool(res), T: TypeBool}, nil
+}
+
+// (set-union set1 set2)
+func (in *Interpret) FSetUnion(args []Param) (*Param, error) {
+	return in.setOp("FSetUnion", args, (*Set).Union, in.SetUnionArgs)
+}
+
+// (set-intersection set1 set2)
+func (in *Interpret) FSetIntersection(args []Param) (*Param, error) {
+	return in.setOp("FSetIntersection", args, (*Set).Intersection, in.SetsArgs)
+}
+
+// (set-difference set1 set2)
+func (in *Interpret) FSetDifference(args []Param) (*Param, error) {
+	return in.setOp("FSetDifference", args, (*Set).Difference, in.SetsArgs)
+}
+
+func (in *Interpret) setOp(name string, args []Param, op func(a, b *Set) (*Set, error), typer func([]Param) (Type, error)) (*Param, error) {
+	if len(args) != 2 {
+		return nil, fmt.Errorf("%v: expected 2 arguments, found %v", name, args)
+	}
+	a, ok := args[0].V.(*Set)
+	if !ok {
+		return nil, fmt.Errorf("%v: expected first argument to be Set, found %v", name, args[0])
+	}
+	b, ok := args[1].V.(*Set)
+	if !ok {
+		return nil, fmt.Errorf("%v: expected second argument to be Set, found %v", name, args[1])
+	}
+	t, err := typer(args)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %w", name, err)
+	}
+	res, err := op(a, b)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %w", name, err)
+	}
+	return &Param{V: res, T: t}, nil
+}
+
+// (set-size set)
+func (in *Interpret) FSetSize(args []Param) (*Param, error) {
+	if len(args) != 1 {
+		return nil, fmt.Errorf("FSetSize: expected 1 argument, found %v", args)
+	}
+	s, ok := args[0].V.(*Set)
+	if !ok {
+		return nil, fmt.Errorf("FSetSize: expected argument to be Set, found %v", args[0])
+	}
+	return &Param{V: in.intMaker.MakeInt(int64(s.Length())), T: TypeInt}, nil
+}
+
+// (set-items set)
+func (in *Interpret) FSetItems(args []Param) (*Param, error) {
+	if len(args) != 1 {
+		return nil, fmt.Errorf("FSetItems: expected 1 argument, found %v", args)
+	}
+	s, ok := args[0].V.(*Set)
+	if !ok {
+		return nil, fmt.Errorf("FSetItems: expected argument to be Set, found %v", args[0])
+	}
+	t, err := in.SetItemsArgs(args)
+	if err != nil {
+		return nil, fmt.Errorf("FSetItems: %w", err)
+	}
+	res := &Sexpr{Quoted: true, List: make([]Param, 0, s.Length())}
+	s.each(func(p *Param) error {
+		res.List = append(res.List, *p)
+		return nil
+	})
+	return &Param{V: res, T: t}, nil
+}
+
+// Typers
+
+func (in *Interpret) setElem(t Type) (Type, error) {
+	return in.elemType(t, TypeSet)
+}
+
+func (in *Interpret) SetOfArgs(params []Param) (Type, error) {
+	elem := TypeNothing
+	for _, p := range params {
+		elem = in.joinTypes(elem, p.T)
+	}
+	return Type("set[" + in.typeArg(elem) + "]"), nil
+}
+
+func (in *Interpret) ListToSetArgs(params []Param) (Type, error) {
+	if len(params) != 1 {
+		return TypeUnknown, fmt.Errorf("expected 1 argument, found %v", params)
+	}
+	elem, err := in.elemType(params[0].T, TypeList)
+	if err != nil {
+		return TypeUnknown, err
+	}
+	return Type("set[" + in.typeArg(elem) + "]"), nil
+}
+
+// SetElemArgs checks arguments of functions like (set-insert set value).
+func (in *Interpret) SetElemArgs(params []Param) (Type, error) {
+	if len(params) != 2 {
+		return TypeUnknown, fmt.Errorf("expected 2 arguments, found %v", params)
+	}
+	elem, err := in.setElem(params[0].T)
+	if err != nil {
+		return TypeUnknown, err
+	}
+	if err := in.expectType("value", params[1], elem); err != nil {
+		return TypeUnknown, err
+	}
+	return params[0].T, nil
+}
+
+func (in *Interpret) SetContainsArgs(params []Param) (Type, error) {
+	if _, err := in.SetElemArgs(params); err != nil {
+		return TypeUnknown, err
+	}
+	return TypeBool, nil
+}
+
+// SetsArgs checks that function is called with two sets. Result is of type of the first set.
+func (in *Interpret) SetsArgs(params []Param) (Type, error) {
+	if len(params) != 2 {
+		return TypeUnknown, fmt.Errorf("expected 2 arguments, found %v", params)
+	}
+	for _, p := range params {
+		if _, err := in.setElem(p.T); err != nil {
+			return TypeUnknown, err
+		}
+	}
+	return params[0].T, nil
+}
+
+func (in *Interpret) SetUnionArgs(params []Param) (Type, error) {
+	if _, err := in.SetsArgs(params); err != nil {
+		return TypeUnknown, err
+	}
+	e1, _ := in.setElem(params[0].T)
+	e2, _ := in.setElem(params[1].T)
+	if e1 == TypeUnknown || e2 == TypeUnknown {
+		return TypeUnknown, nil
+	}
+	return Type("set[" + in.typeArg(in.joinTypes(e1, e2)) + "]"), nil
+}
+
+func (in *Interpret) SetItemsArgs(params []Param) (Type, error) {
+	if len(params) != 1 {
+		return TypeUnknown, fmt.Errorf("expected 1 argument, found %v", params)
+	}
+	elem, err := in.setElem(params[0].T)
+	if err != nil {
+		return TypeUnknown, err
+	}
+	if elem == TypeUnknown {
+		return TypeList, nil
+	}
+	return Type("list[" + elem + "]"), nil
+}
+
+func (in *Interpret) SetSizeArgs(params []Param) error {
+	if len(params) != 1 {
+		return fmt.Errorf("expected 1 argument, found %v", params)
+	}
+	_, err := in.setElem(params[0].T)
+	return err
+}
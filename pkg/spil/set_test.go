@@ -0,0 +1,84 @@
+package spil
+
+import (
+	"testing"
+)
+
+func intSet(t *testing.T, items ...int) *Set {
+	params := make([]Param, len(items))
+	for i, item := range items {
+		params[i] = intParam(item)
+	}
+	s, err := NewSet(params...)
+	if err != nil {
+		t.Fatalf("NewSet() failed: %v", err)
+	}
+	return s
+}
+
+func TestSetOperations(t *testing.T) {
+	a := intSet(t, 1, 2, 3, 4, 3, 2)
+	b := intSet(t, 3, 4, 5, 6)
+	if a.Length() != 4 {
+		t.Errorf("Incorrect set size: expected 4, actual %v", a.Length())
+	}
+	tests := []struct {
+		name string
+		op   func(a, b *Set) (*Set, error)
+		exp  *Set
+	}{
+		{"Union", (*Set).Union, intSet(t, 1, 2, 3, 4, 5, 6)},
+		{"Intersection", (*Set).Intersection, intSet(t, 3, 4)},
+		{"Difference", (*Set).Difference, intSet(t, 1, 2)},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			act, err := test.op(a, b)
+			if err != nil {
+				t.Fatalf("%v failed: %v", test.name, err)
+			}
+			if !Equal(act, test.exp) {
+				t.Errorf("Incorrect result: expected %v, actual %v", test.exp, act)
+			}
+		})
+	}
+	// original sets are not changed
+	if !Equal(a, intSet(t, 1, 2, 3, 4)) || !Equal(b, intSet(t, 3, 4, 5, 6)) {
+		t.Errorf("Original sets changed: %v, %v", a, b)
+	}
+}
+
+func TestSetInsertRemove(t *testing.T) {
+	s := intSet(t, 1, 2)
+	s2, _ := s.Insert(intParam(3))
+	s3, _ := s2.Remove(intParam(1))
+	for _, test := range []struct {
+		s    *Set
+		item int
+		exp  bool
+	}{
+		{s, 3, false},
+		{s2, 3, true},
+		{s2, 1, true},
+		{s3, 1, false},
+		{s3, 2, true},
+	} {
+		if act, err := test.s.Contains(intParam(test.item)); err != nil || act != test.exp {
+			t.Errorf("Contains(%v) in %v: expected %v, actual %v (%v)", test.item, test.s, test.exp, act, err)
+		}
+	}
+	if !Equal(intSet(t, 1, 2, 3), intSet(t, 3, 2, 1)) {
+		t.Errorf("Sets with the same elements are not equal")
+	}
+}
+
+func TestSetTypes(t *testing.T) {
+	tests := []programTest{
+		{"builtin", "(print (type (set-of 1 2 3)) (type (set-of)))", ":set[int] :set[any]\n", ""},
+		{"user set", "(deftype :set :list)\n(print (type (do '(1) :set)) (type (set-of 1)))", ":set :set[int]\n", ""},
+		{"redefined", "(deftype :set[a] :list[a])\n(print (type (do '(1 2) :set[int])))", ":set[int]\n", ""},
+		{"redefined twice", "(deftype :set[a] :list[a])\n(deftype :set[a] :list[a])", "", "Cannot redefine type :set[a]"},
+		{"other builtin", "(deftype :map[a,b] :list[a])", "", "Cannot redefine type :map[a,b]"},
+	}
+	checkPrograms(t, tests)
+}
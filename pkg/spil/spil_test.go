@@ -0,0 +1,181 @@
+package spil
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// Examples refer to files relatively to the root of repository.
+func TestMain(m *testing.M) {
+	if err := os.Chdir(filepath.Join(getTestLibraryDir(), "..")); err != nil {
+		panic(err)
+	}
+	os.Exit(m.Run())
+}
+
+func TestExamples(t *testing.T) {
+	inputs, err := filepath.Glob("examples/ex.*")
+	if err != nil {
+		panic(err)
+	}
+
+	for _, test := range inputs {
+		for _, bigint := range []bool{false, true} {
+			name := test
+			if bigint {
+				name += "-big"
+			}
+			t.Run(name, func(t *testing.T) {
+				dir, file := filepath.Split(test)
+				output := filepath.Join(dir, "out"+file[2:])
+				checkInterpreter(t, test, output, bigint)
+			})
+		}
+	}
+}
+
+func TestBuiltin(t *testing.T) {
+	inputs, err := filepath.Glob("examples/builtin.*")
+	if err != nil {
+		panic(err)
+	}
+	for _, test := range inputs {
+		for _, bigint := range []bool{false, true} {
+			name := test
+			if bigint {
+				name += "-big"
+			}
+			t.Run(name, func(t *testing.T) {
+				dir, file := filepath.Split(test)
+				output := filepath.Join(dir, "output."+file)
+				checkInterpreter(t, test, output, bigint)
+			})
+		}
+	}
+}
+
+func checkInterpreter(t *testing.T, input, output string, bigint bool) {
+	fin, err := os.Open(input)
+	if err != nil {
+		t.Fatalf("Cannot open input file: %v", err)
+	}
+	defer fin.Close()
+
+	expData, err := ioutil.ReadFile(output)
+	if os.IsNotExist(err) {
+		t.Skipf("No output file for %v found: %v", input, output)
+	}
+	if err != nil {
+		t.Fatalf("Reading output file failed: %v", err)
+	}
+
+	buffer := &strings.Builder{}
+	in := NewInterpreter(buffer, getTestLibraryDir())
+	in.UseBigInt(bigint)
+
+	inputPath, err := filepath.Abs(input)
+	if err != nil {
+		t.Fatalf("Abs(%v) failed: %v", input, err)
+	}
+	if err := run(in, inputPath, fin); err != nil {
+		t.Fatalf("Interpreter Run() failed: %v", err)
+	}
+
+	if act, exp := buffer.String(), string(expData); act != exp {
+		t.Errorf("Incorrect output for %v:\nexpected %q,\n  actual %q", input, exp, act)
+	}
+}
+
+func run(i *Interpret, file string, input io.Reader) error {
+	if err := i.Parse(file, input); err != nil {
+		return err
+	}
+	if err := i.Check(); err != nil {
+		return fmt.Errorf("Check failed: %v", err)
+	}
+	return i.Run(nil, nil)
+}
+
+func getTestLibraryDir() string {
+	_, filename, _, _ := runtime.Caller(0)
+	return filepath.Join(filepath.Dir(filename), "..", "..", "library")
+}
+
+func TestErrorPositions(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		exp   string
+	}{
+		{"parse", "(print 1)\n(print 2", "test.lisp:2:1: "},
+		{"check", "(def foo (n:int) :int (+ n 1))\n(print\n  (foo \"x\"))", "test.lisp:3:3: "},
+		{"undefined", "(def foo (n:int) :int\n\t(+ n x))", "test.lisp:2:7: "},
+		{"unknown function", "(print 1)\n(print (nofn 2))", "test.lisp:2:8: __main__: unknown function nofn"},
+		{"runtime", "(def foo (n:int) :int\n  (print (native.head '()))\n  1)\n(print (foo 1))", "test.lisp:2:10: "},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			in := NewInterpreter(ioutil.Discard, getTestLibraryDir())
+			err := in.Parse("test.lisp", strings.NewReader(test.input))
+			if err == nil {
+				if errs := in.Check(); len(errs) > 0 {
+					err = errs[0]
+				}
+			}
+			if err == nil {
+				err = in.Run(nil, nil)
+			}
+			if err == nil {
+				t.Fatalf("Error expected")
+			}
+			if !strings.HasPrefix(err.Error(), test.exp) {
+				t.Errorf("Incorrect error position: expected prefix %q, actual %q", test.exp, err.Error())
+			}
+		})
+	}
+}
+
+// programTest is a program with its expected output or (if err is set) a part of expected error.
+type programTest struct {
+	name  string
+	input string
+	exp   string
+	err   string
+}
+
+// checkPrograms parses, checks and runs programs and compares their output with expected one.
+func checkPrograms(t *testing.T, tests []programTest) {
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			buffer := &strings.Builder{}
+			in := NewInterpreter(buffer, getTestLibraryDir())
+			err := in.Parse("test.lisp", strings.NewReader(test.input))
+			if err == nil {
+				if errs := in.Check(); len(errs) > 0 {
+					err = errs[0]
+				}
+			}
+			if err == nil {
+				err = in.Run(nil, nil)
+			}
+			if test.err != "" {
+				if err == nil || !strings.Contains(err.Error(), test.err) {
+					t.Errorf("Incorrect error: expected %q, actual %v", test.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Program failed: %v", err)
+			}
+			if act := buffer.String(); act != test.exp {
+				t.Errorf("Incorrect output: expected %q, actual %q", test.exp, act)
+			}
+		})
+	}
+}
@@ -0,0 +1,192 @@
+package spil
+
+import (
+	"fmt"
+	"strings"
+)
+
+// TypeExpr is parsed representation of a type: type constructor and its type arguments,
+// e.g. :map[str,list[int]] is constructor "map" applied to :str and :list[int].
+// Type variables (types declared by contracts, e.g. :a) are types without arguments;
+// whether the name is a type variable is decided by the interpreter (see isTypeVar).
+type TypeExpr struct {
+	Name string
+	Args []*TypeExpr
+}
+
+// ParseTypeExpr parses type (without leading colon) like "func[func[a,b],list[a],list[b]]".
+func ParseTypeExpr(s string) (*TypeExpr, error) {
+	p := &typeParser{s: s}
+	te, err := p.parse()
+	if err != nil {
+		return nil, fmt.Errorf("Incorrect type :%v: %w", s, err)
+	}
+	if p.pos < len(s) {
+		return nil, fmt.Errorf("Incorrect type :%v: unexpected %q at position %v", s, s[p.pos], p.pos)
+	}
+	return te, nil
+}
+
+type typeParser struct {
+	s   string
+	pos int
+}
+
+func (p *typeParser) parse() (*TypeExpr, error) {
+	start := p.pos
+	for p.pos < len(p.s) && !strings.ContainsRune("[],", rune(p.s[p.pos])) {
+		p.pos++
+	}
+	if p.pos == start {
+		if p.pos == len(p.s) {
+			return nil, fmt.Errorf("unexpected end of type")
+		}
+		return nil, fmt.Errorf("expected type name at position %v, found %q", p.pos, p.s[p.pos])
+	}
+	te := &TypeExpr{Name: p.s[start:p.pos]}
+	if p.pos == len(p.s) || p.s[p.pos] != '[' {
+		return te, nil
+	}
+	for {
+		// skip '[' or ','
+		p.pos++
+		arg, err := p.parse()
+		if err != nil {
+			return nil, err
+		}
+		te.Args = append(te.Args, arg)
+		if p.pos == len(p.s) {
+			return nil, fmt.Errorf("expected ']' at the end")
+		}
+		switch p.s[p.pos] {
+		case ',':
+			continue
+		case ']':
+			p.pos++
+			return te, nil
+		}
+		return nil, fmt.Errorf("expected ',' or ']' at position %v, found %q", p.pos, p.s[p.pos])
+	}
+}
+
+// Expr returns parsed type.
+// Malformed types (which are reported by parseType) are returned as types without arguments.
+func (t Type) Expr() *TypeExpr {
+	te, err := ParseTypeExpr(string(t))
+	if err != nil {
+		return &TypeExpr{Name: string(t)}
+	}
+	return te
+}
+
+// Type returns string representation of the type which is used as a key of maps of types.
+func (te *TypeExpr) Type() Type {
+	if len(te.Args) == 0 {
+		return Type(te.Name)
+	}
+	b := &strings.Builder{}
+	te.write(b)
+	return Type(b.String())
+}
+
+func (te *TypeExpr) write(b *strings.Builder) {
+	b.WriteString(te.Name)
+	if len(te.Args) == 0 {
+		return
+	}
+	b.WriteString("[")
+	for i, a := range te.Args {
+		if i > 0 {
+			b.WriteString(",")
+		}
+		a.write(b)
+	}
+	b.WriteString("]")
+}
+
+func (te *TypeExpr) String() string {
+	return te.Type().String()
+}
+
+// Canonical replaces type arguments with type variables a, b, c...:
+// :x[int,str,list[int]] -> :x[a,b,c]
+func (te *TypeExpr) Canonical() *TypeExpr {
+	res := &TypeExpr{Name: te.Name}
+	for i := range te.Args {
+		res.Args = append(res.Args, &TypeExpr{Name: string(rune('a' + i))})
+	}
+	return res
+}
+
+// Expand replaces type variables with the bound types.
+func (te *TypeExpr) Expand(binds map[string]Type) *TypeExpr {
+	if len(te.Args) == 0 {
+		if t, ok := binds[te.Name]; ok {
+			return t.Expr()
+		}
+		return te
+	}
+	res := &TypeExpr{Name: te.Name, Args: make([]*TypeExpr, len(te.Args))}
+	for i, a := range te.Args {
+		res.Args[i] = a.Expand(binds)
+	}
+	return res
+}
+
+// subst replaces type variables of the canonical type (a, b, c...) with the arguments.
+func (te *TypeExpr) subst(args []*TypeExpr) *TypeExpr {
+	if len(te.Args) == 0 {
+		if len(te.Name) == 1 {
+			if i := int(te.Name[0]) - 'a'; i >= 0 && i < len(args) {
+				return args[i]
+			}
+		}
+		return te
+	}
+	res := &TypeExpr{Name: te.Name, Args: make([]*TypeExpr, len(te.Args))}
+	for i, a := range te.Args {
+		res.Args[i] = a.subst(args)
+	}
+	return res
+}
+
+// Interpreter
+
+// typeExpr returns parsed type.
+// Parsed types are cached: they are never modified (Expand and subst create new ones).
+func (in *Interpret) typeExpr(t Type) *TypeExpr {
+	te, ok := in.typeExprs[t]
+	if !ok {
+		te = t.Expr()
+		in.typeExprs[t] = te
+	}
+	return te
+}
+
+func (in *Interpret) isTypeVar(te *TypeExpr) bool {
+	return len(te.Args) == 0 && in.IsContract(Type(te.Name))
+}
+
+// unaliasExpr replaces aliases like :list with the types they stand for (:list[any]).
+func (in *Interpret) unaliasExpr(te *TypeExpr) *TypeExpr {
+	if len(te.Args) > 0 {
+		return te
+	}
+	if t, ok := in.typeAliases[Type(te.Name)]; ok {
+		return in.typeExpr(t)
+	}
+	return te
+}
+
+// parentExpr returns parent of the type with type arguments of te,
+// e.g. :vector[int] -> :list[int]. Nil is returned for types without parent.
+func (in *Interpret) parentExpr(te *TypeExpr) (*TypeExpr, error) {
+	parent, ok := in.types[te.Canonical().Type()]
+	if !ok {
+		return nil, fmt.Errorf("%v is not defined", te)
+	}
+	if parent == "" {
+		return nil, nil
+	}
+	return in.typeExpr(parent).subst(te.Args), nil
+}
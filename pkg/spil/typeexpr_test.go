@@ -0,0 +1,91 @@
+package spil
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseTypeExpr(t *testing.T) {
+	tests := []struct {
+		arg  string
+		exp  *TypeExpr
+		args int
+	}{
+		{"int", &TypeExpr{Name: "int"}, 0},
+		{"map[str,list[int]]", &TypeExpr{Name: "map", Args: []*TypeExpr{
+			{Name: "str"},
+			{Name: "list", Args: []*TypeExpr{{Name: "int"}}},
+		}}, 2},
+		{"func[func[a,b],list[a],list[b]]", &TypeExpr{Name: "func", Args: []*TypeExpr{
+			{Name: "func", Args: []*TypeExpr{{Name: "a"}, {Name: "b"}}},
+			{Name: "list", Args: []*TypeExpr{{Name: "a"}}},
+			{Name: "list", Args: []*TypeExpr{{Name: "b"}}},
+		}}, 3},
+	}
+	for _, test := range tests {
+		t.Run(test.arg, func(t *testing.T) {
+			act, err := ParseTypeExpr(test.arg)
+			if err != nil {
+				t.Fatalf("ParseTypeExpr(%q) failed: %v", test.arg, err)
+			}
+			if act.String() != test.exp.String() || len(act.Args) != test.args {
+				t.Errorf("ParseTypeExpr(%q) failed: expected %v, actual %v", test.arg, test.exp, act)
+			}
+			if string(act.Type()) != test.arg {
+				t.Errorf("%v.Type() failed: expected %v, actual %v", act, test.arg, act.Type())
+			}
+		})
+	}
+}
+
+func TestParseTypeExprErrors(t *testing.T) {
+	tests := []struct {
+		arg string
+		exp string
+	}{
+		{"", "unexpected end of type"},
+		{"list[int", "expected ']' at the end"},
+		{"list[]", "expected type name at position 5, found ']'"},
+		{"map[int,]", "expected type name at position 8, found ']'"},
+		{"list[int]]", "unexpected ']' at position 9"},
+	}
+	for _, test := range tests {
+		t.Run(test.arg, func(t *testing.T) {
+			_, err := ParseTypeExpr(test.arg)
+			if err == nil || !strings.Contains(err.Error(), test.exp) {
+				t.Errorf("Incorrect error: expected %q, actual %v", test.exp, err)
+			}
+		})
+	}
+}
+
+func TestTypeExprExpand(t *testing.T) {
+	tests := []struct {
+		arg   Type
+		binds map[string]Type
+		exp   Type
+	}{
+		{"a", map[string]Type{"a": "int"}, "int"},
+		{"func[func[a,b],list[a],list[b]]", map[string]Type{"a": "int", "b": "list[str]"}, "func[func[int,list[str]],list[int],list[list[str]]]"},
+		{"map[str,list[a]]", map[string]Type{"b": "int"}, "map[str,list[a]]"},
+	}
+	for _, test := range tests {
+		t.Run(string(test.arg), func(t *testing.T) {
+			if act := test.arg.Expand(test.binds); act != test.exp {
+				t.Errorf("%v.Expand(%v) failed: expected %v, actual %v", test.arg, test.binds, test.exp, act)
+			}
+		})
+	}
+}
+
+func TestNestedGenerics(t *testing.T) {
+	tests := []programTest{
+		{"map of lists", "(def f (m:map[str,list[int]]) :list[int] (map-get m \"a\"))\n(print (f (map-of \"a\" (list 1 2))))", "'(1 2)\n", ""},
+		{"list of functions", "(def call (f:func[int,int] x:int) :int (f x))\n(def f (fs:list[func[int,int]] x:int) :int (call (head fs) x))\n(print (f (list (lambda (x:int) :int (+ x 1))) 1))", "2\n", ""},
+		{"higher order", "(use std)\n(def my-map (f:func[a,b] l:list[a]) :list[b] (map f l))\n" +
+			"(def twice (m:func[func[a,b],list[a],list[b]] f:func[a,b] l:list[a]) :list[b] (m f (m f l)))\n" +
+			"(print (twice my-map (lambda (x:int) :int (* x 2)) '(1 2)))", "'(4 8)\n", ""},
+		{"incorrect type", "(def f (x:list[int) :int 1)", "", "Incorrect type :list[int: expected ']' at the end"},
+	}
+	checkPrograms(t, tests)
+}
@@ -0,0 +1,314 @@
+package spil
+
+import (
+	"fmt"
+	"strings"
+)
+
+type Type string
+
+const (
+	TypeUnknown  Type = "unknown"
+	TypeAny      Type = "any"
+	TypeInt      Type = "int"
+	TypeFloat    Type = "float"
+	TypeRational Type = "rational"
+	TypeStr      Type = "str"
+	TypeBool     Type = "bool"
+	TypeFunc     Type = "func"
+	TypeList     Type = "list"
+	TypeMap      Type = "map"
+	TypeVector   Type = "vector"
+	// note that there is no alias for :set (it is often defined by user)
+	TypeSet   Type = "set"
+	TypeError Type = "error"
+	// type of expressions which never return (e.g. raising an error)
+	TypeNothing Type = "nothing"
+)
+
+// noTypeInfo returns true for types which do not restrict type variables
+// (e.g. type of elements of empty list).
+func noTypeInfo(t Type) bool {
+	return t == TypeUnknown || t == TypeNothing
+}
+
+func (t Type) String() string {
+	return ":" + string(t)
+}
+
+// ":list[a]" -> "list"
+func (t Type) Basic() string {
+	res := string(t)
+	if p := strings.Index(res, "["); p >= 0 {
+		res = res[:p]
+	}
+	return res
+}
+
+// ":tuple[a,list[b],c]" -> ["a", "list[b]", "c"]
+func (t Type) Arguments() []Type {
+	if !strings.Contains(string(t), "[") {
+		return nil
+	}
+	var res []Type
+	for _, a := range t.Expr().Args {
+		res = append(res, a.Type())
+	}
+	return res
+}
+
+// ":x[int,str,list[int]]" -> "x[a,b,c]"
+func (t Type) Canonical() Type {
+	if !strings.Contains(string(t), "[") {
+		return t
+	}
+	return t.Expr().Canonical().Type()
+}
+
+func (t Type) Expand(types map[string]Type) Type {
+	if len(types) == 0 {
+		return t
+	}
+	return t.Expr().Expand(types).Type()
+}
+
+func ParseType(token string) (Type, bool) {
+	if strings.HasPrefix(token, ":") {
+		return Type(token[1:]), true
+	}
+	return TypeUnknown, false
+}
+
+type ArgFmt struct {
+	Args []Arg
+	// list argument that matches everything
+	Wildcard string
+}
+
+func (a *ArgFmt) Values() map[string]Type {
+	m := make(map[string]Type)
+	if a.Wildcard != "" {
+		m[a.Wildcard] = TypeList
+	} else {
+		addArgValues(m, a.Args)
+	}
+	return m
+}
+
+func addArgValues(m map[string]Type, args []Arg) {
+	for _, arg := range args {
+		if arg.Pattern != nil {
+			addArgValues(m, arg.Pattern.Args)
+			if arg.Pattern.Tail != nil {
+				addArgValues(m, []Arg{*arg.Pattern.Tail})
+			}
+		} else if arg.Name != "" {
+			m[arg.Name] = arg.T
+		}
+	}
+}
+
+// "(n:int '() 1)"
+func (a *ArgFmt) String() string {
+	if a.Wildcard != "" {
+		return a.Wildcard
+	}
+	b := &strings.Builder{}
+	b.WriteString("(")
+	writeArgs(b, a.Args)
+	b.WriteString(")")
+	return b.String()
+}
+
+func writeArgs(b *strings.Builder, args []Arg) {
+	for i, arg := range args {
+		if i > 0 {
+			b.WriteString(" ")
+		}
+		if arg.V != nil {
+			arg.V.Print(b)
+			continue
+		}
+		if p := arg.Pattern; p != nil {
+			b.WriteString("(")
+			if p.Variant != "" {
+				b.WriteString(p.Variant.String())
+				if len(p.Args) > 0 {
+					b.WriteString(" ")
+				}
+			}
+			writeArgs(b, p.Args)
+			if p.Tail != nil {
+				b.WriteString(" . ")
+				writeArgs(b, []Arg{*p.Tail})
+			}
+			b.WriteString(")")
+			continue
+		}
+		b.WriteString(arg.Name)
+		if arg.T != TypeUnknown {
+			b.WriteString(arg.T.String())
+		}
+	}
+}
+
+func MakeArgFmt(args ...Arg) (a *ArgFmt) {
+	a = &ArgFmt{}
+	for _, arg := range args {
+		a.Args = append(a.Args, arg)
+	}
+	return
+}
+
+func MakeWildcard(name string) *ArgFmt {
+	return &ArgFmt{Wildcard: name}
+}
+
+type Arg struct {
+	Name string
+	T    Type
+	V    Expr
+	// Pattern destructures the argument (e.g. (:ok value)), nil for simple arguments.
+	Pattern *Pattern
+}
+
+// Pattern matches variant of algebraic data type and binds its fields to Args
+// or matches list and binds its elements to Args, e.g. (a b c) or (head . tail).
+type Pattern struct {
+	// empty for list patterns
+	Variant Type
+	Args    []Arg
+	// rest of the list in patterns like (head . tail)
+	Tail *Arg
+}
+
+func ParseArgFmt(argfmt Expr) (*ArgFmt, error) {
+	switch a := argfmt.(type) {
+	case Ident:
+		// pass arguments as list with specified name
+		return &ArgFmt{Wildcard: string(a)}, nil
+	case *Sexpr:
+		// bind arguments
+		args, err := parseArgs(a.List)
+		if err != nil {
+			return nil, err
+		}
+		return &ArgFmt{Args: args}, nil
+	default:
+		return nil, fmt.Errorf("Expected arguments signature, found: %v", argfmt)
+	}
+
+}
+
+func parseArgs(list []Param) ([]Arg, error) {
+	var result []Arg
+	for _, arg := range list {
+		switch r := arg.V.(type) {
+		case Int:
+			result = append(result, Arg{"", TypeInt, arg.V, nil})
+		case Float:
+			result = append(result, Arg{"", TypeFloat, arg.V, nil})
+		case Str:
+			result = append(result, Arg{"", TypeStr, arg.V, nil})
+		case Bool:
+			result = append(result, Arg{"", TypeBool, arg.V, nil})
+		case *Sexpr:
+			if r.Empty() {
+				result = append(result, Arg{"", TypeList, arg.V, nil})
+				continue
+			}
+			pattern, err := parsePattern(r)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, Arg{"", TypeUnknown, nil, pattern})
+		case Ident:
+			if colon := strings.Index(string(r), ":"); colon >= 0 {
+				tp, ok := ParseType(string(r)[colon:])
+				if !ok {
+					return nil, fmt.Errorf("Unknown type is specified in argument %v", arg)
+				}
+				if _, err := ParseTypeExpr(string(tp)); err != nil {
+					return nil, err
+				}
+				result = append(result, Arg{string(r)[:colon], tp, nil, nil})
+			} else {
+				result = append(result, Arg{string(r), TypeUnknown, nil, nil})
+			}
+		}
+	}
+	return result, nil
+}
+
+// (:variant field1 field2 ...), (elem1 elem2 ...) or (elem1 elem2 ... . tail)
+func parsePattern(se *Sexpr) (*Pattern, error) {
+	if id, ok := se.List[0].V.(Ident); ok {
+		if variant, ok := ParseType(string(id)); ok {
+			fields, err := parseArgs(se.List[1:])
+			if err != nil {
+				return nil, err
+			}
+			return &Pattern{Variant: variant, Args: fields}, nil
+		}
+	}
+	elems := se.List
+	var tail *Arg
+	for i, e := range se.List {
+		if e.V != Ident(".") {
+			continue
+		}
+		if i == 0 || i != len(se.List)-2 {
+			return nil, fmt.Errorf("Incorrect list pattern: %v", se)
+		}
+		t, err := parseArgs(se.List[i+1:])
+		if err != nil {
+			return nil, err
+		}
+		if t[0].V != nil || t[0].Pattern != nil {
+			return nil, fmt.Errorf("Incorrect list pattern: tail should be a variable, found %v", se.List[i+1])
+		}
+		tail = &t[0]
+		elems = se.List[:i]
+	}
+	args, err := parseArgs(elems)
+	if err != nil {
+		return nil, err
+	}
+	return &Pattern{Args: args, Tail: tail}, nil
+}
+
+type Param struct {
+	T Type
+	V Expr
+	// position in the source file (if known)
+	Pos *Pos
+}
+
+func (p Param) String() string {
+	return fmt.Sprintf("{%v %v}", p.T, p.V)
+}
+
+func MakeParametersFromArgs(args []Expr) (res []Param) {
+	for _, arg := range args {
+		p := Param{V: arg}
+		switch arg.(type) {
+		case Int:
+			p.T = TypeInt
+		case Float:
+			p.T = TypeFloat
+		case Str:
+			p.T = TypeStr
+		case Bool:
+			p.T = TypeBool
+		case List:
+			p.T = TypeList
+		case Ident:
+			// TODO maybe check that function exist
+			p.T = TypeFunc
+		default:
+			panic(fmt.Errorf("Unexpected Expr type: %v (%v)", arg, arg))
+		}
+		res = append(res, p)
+	}
+	return
+}
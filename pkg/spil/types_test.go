@@ -1,4 +1,4 @@
-package main
+package spil
 
 import (
 	"reflect"
@@ -26,11 +26,13 @@ func TestBasic(t *testing.T) {
 func TestArguments(t *testing.T) {
 	tests := []struct {
 		arg Type
-		exp []string
+		exp []Type
 	}{
 		{":int", nil},
-		{":list[a]", []string{"a"}},
-		{":list[a,b,c]", []string{"a", "b", "c"}},
+		{":list[a]", []Type{"a"}},
+		{":list[a,b,c]", []Type{"a", "b", "c"}},
+		{"map[str,list[int]]", []Type{"str", "list[int]"}},
+		{"func[func[a,b],list[a],list[b]]", []Type{"func[a,b]", "list[a]", "list[b]"}},
 	}
 	for _, test := range tests {
 		t.Run(string(test.arg), func(t *testing.T) {
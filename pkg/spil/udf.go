@@ -1,10 +1,11 @@
-package main
+package spil
 
 import (
 	"fmt"
 	"io"
 	"os"
 	"regexp"
+	"runtime"
 	"strings"
 )
 
@@ -16,6 +17,9 @@ type FuncInterpret struct {
 	bodies       []*FuncImpl
 	returnType   Type
 	capturedVars map[string]*Param
+	// builtin function which is called if none of implementations matches
+	// (builtin functions may be extended with user implementations, e.g. print for user types)
+	native Evaler
 }
 
 func (f *FuncInterpret) FuncType() Type {
@@ -40,6 +44,12 @@ type FuncImpl struct {
 	returnType Type
 	// function type
 	funcType Type
+	// position of definition in source file
+	pos *Pos
+	// optional guard expression: implementation is used only if it evaluates to true
+	guard *Param
+	// indices of previously declared implementations with less specific argument types
+	overrides map[int]bool
 }
 
 func NewFuncImpl(argfmt *ArgFmt, body []Param, memo bool, returnType Type) *FuncImpl {
@@ -106,7 +116,11 @@ func (f *FuncInterpret) AddImpl(argfmt Expr, body []Param, memo bool, returnType
 	if err != nil {
 		return err
 	}
+	if err := f.interpret.resolvePatterns(af.Args); err != nil {
+		return fmt.Errorf("%v: %w", f.name, err)
+	}
 	f.bodies = append(f.bodies, NewFuncImpl(af, body, memo, returnType))
+	f.setOverrides()
 
 	f.returnType = returnType
 	return nil
@@ -117,34 +131,130 @@ func (f *FuncInterpret) AddVar(name string, p *Param) {
 }
 
 func (f *FuncInterpret) TryBind(params []Param) (num int, rt Type, types map[string]Type, err error) {
+	var contractErr error
 	for idx, im := range f.bodies {
-		if ok, types := f.matchParameters(im.argfmt, params); ok {
-			t := im.returnType.Expand(types)
-			if len(types) > 0 {
-				// check that generics are matching
-				values := map[string]Type{}
-				for i, arg := range im.argfmt.Args {
-					values[arg.Name] = params[i].T
+		ok, types, err := f.matchImpl(im, params)
+		if err != nil {
+			if isContractError(err) {
+				if contractErr == nil {
+					contractErr = err
 				}
-				tt, err := f.interpret.evalBodyType(f.name, im.body, values, types)
-				if newTt, ok := types[tt.Basic()]; ok {
-					tt = newTt
+				continue
+			}
+			return -1, "", nil, err
+		}
+		if !ok {
+			continue
+		}
+		// more specific implementation declared later takes precedence
+		for j := idx + 1; j < len(f.bodies); j++ {
+			if !f.bodies[j].overrides[idx] {
+				continue
+			}
+			ok, jtypes, err := f.matchImpl(f.bodies[j], params)
+			if err != nil && !isContractError(err) {
+				return -1, "", nil, err
+			}
+			if ok && err == nil {
+				idx, types = j, jtypes
+			}
+		}
+		im = f.bodies[idx]
+		if len(types) > 0 {
+			// type variables which are not bound by arguments (e.g. return type of :func[a,b]) are :any
+			f.interpret.addFreeVars(im.returnType, types)
+		}
+		t := im.returnType.Expand(types)
+		if in := f.interpret; len(types) > 0 && !in.rechecking[im] {
+			// check that generics are matching
+			values := im.argfmt.Values()
+			for i, arg := range im.argfmt.Args {
+				if arg.Pattern == nil {
+					values[arg.Name] = params[i].T
+					if params[i].T == TypeUnknown {
+						// e.g. generic function passed as argument is instantiated with bound types
+						values[arg.Name] = arg.T.Expand(types)
+					}
 				}
+			}
+			// types declared in the body (e.g. (set x value :list[a])) are instantiated too
+			saved := in.typeBinds
+			in.typeBinds = types
+			in.rechecking[im] = true
+			tt, err := in.evalBodyType(f.name, im.body, values, types)
+			in.typeBinds = saved
+			delete(in.rechecking, im)
+			if newTt, ok := types[tt.Basic()]; ok {
+				tt = newTt
+			}
 
-				if err != nil {
-					return -1, "", nil, err
-				}
-				if t != tt {
-					return -1, "", nil, fmt.Errorf("%v: mismatch return type: declared %v != actual %v", f.name, t, tt)
-				}
+			if err != nil {
+				return -1, "", nil, err
+			}
+			if t != tt && !f.interpret.covariantType(tt, t) {
+				return -1, "", nil, withPos(im.pos, fmt.Errorf("%v: mismatch return type: declared %v != actual %v", f.name, t, tt))
 			}
-			// TODO
-			return idx, t, types, nil
 		}
+		// TODO
+		return idx, t, types, nil
+	}
+	if contractErr != nil {
+		return -1, "", nil, contractErr
+	}
+	if f.native != nil {
+		// index of implementation is -1 for builtin function
+		_, rt, types, err := f.native.TryBind(params)
+		return -1, rt, types, err
 	}
 	return -1, TypeUnknown, nil, fmt.Errorf("%v: no matching function implementation found for %v", f.name, params)
 }
 
+// matchImpl checks if implementation accepts params: types, patterns, contracts and guard.
+func (f *FuncInterpret) matchImpl(im *FuncImpl, params []Param) (bool, map[string]Type, error) {
+	ok, types := f.matchParameters(im.argfmt, params)
+	if !ok {
+		return false, nil, nil
+	}
+	if err := f.interpret.checkContracts(types); err != nil {
+		return false, nil, fmt.Errorf("%v: %w", f.name, err)
+	}
+	if im.guard != nil && hasValues(params) {
+		pass, err := f.checkGuard(im, params)
+		if err != nil {
+			return false, nil, err
+		}
+		if !pass {
+			return false, nil, nil
+		}
+	}
+	return true, types, nil
+}
+
+// hasValues returns false if params are used only for type checking.
+func hasValues(params []Param) bool {
+	for _, p := range params {
+		if p.V == nil {
+			return false
+		}
+	}
+	return true
+}
+
+// checkGuard evaluates guard of implementation with arguments bound to params.
+func (f *FuncInterpret) checkGuard(im *FuncImpl, params []Param) (bool, error) {
+	run := NewFuncRuntime(f)
+	run.bindVars(im, params)
+	res, err := run.evalParameter(im.guard)
+	if err != nil {
+		return false, withPos(im.guard.Pos, fmt.Errorf("%v: guard failed: %w", f.name, err))
+	}
+	b, ok := res.V.(Bool)
+	if !ok {
+		return false, withPos(im.guard.Pos, fmt.Errorf("%v: guard should evaluate to boolean value, actual %v", f.name, res))
+	}
+	return bool(b), nil
+}
+
 func (f *FuncInterpret) Eval(params []Param) (result *Param, err error) {
 	run := NewFuncRuntime(f)
 	impl, result, rt, types, err := run.bind(params)
@@ -157,7 +267,7 @@ func (f *FuncInterpret) Eval(params []Param) (result *Param, err error) {
 	run.types = types
 	res, err := run.Eval(impl)
 	if err != nil {
-		return nil, err
+		return nil, addFrame(err, run.frame())
 	}
 	run.cleanup()
 	newT, err := run.updateType(res.T, rt)
@@ -165,7 +275,7 @@ func (f *FuncInterpret) Eval(params []Param) (result *Param, err error) {
 		return nil, fmt.Errorf("Cannot cast type %v to %v: %v", res.T, rt, err)
 	}
 	res.T = newT
-	return res, err
+	return f.interpret.promote(res, newT), err
 }
 
 func (f *FuncInterpret) ReturnType() Type {
@@ -179,6 +289,18 @@ type FuncRuntime struct {
 	// variables that should be Closed after leaving this variable scope.
 	scopedVars []string
 	types      map[string]Type
+	// currently evaluated implementation
+	impl *FuncImpl
+	// number of performed tail calls
+	tailCalls int
+	// variables bound by 'let' with their previous values
+	shadowed []shadowedVar
+}
+
+type shadowedVar struct {
+	name  string
+	value Param
+	ok    bool
 }
 
 func NewFuncRuntime(fi *FuncInterpret) *FuncRuntime {
@@ -210,7 +332,12 @@ func (f *FuncRuntime) bind(params []Param) (impl *FuncImpl, result *Param, resul
 	if err != nil {
 		return nil, nil, "", nil, err
 	}
+	if idx < 0 {
+		result, err = f.fi.native.Eval(params)
+		return nil, result, "", nil, err
+	}
 	impl = f.fi.bodies[idx]
+	f.impl = impl
 	if impl.memo {
 		keyArgs, err := keyOfArgs(args)
 		if err != nil {
@@ -220,6 +347,19 @@ func (f *FuncRuntime) bind(params []Param) (impl *FuncImpl, result *Param, resul
 		}
 	}
 
+	if impl.argfmt != nil && impl.argfmt.Wildcard == "" {
+		if l := len(impl.argfmt.Args); l != len(params) {
+			err = fmt.Errorf("Incorrect number of arguments to %v: expected %v, found %v", f.fi.name, l, len(params))
+			return
+		}
+	}
+	f.bindVars(impl, params)
+	f.args = args
+	return impl, nil, rt, types, nil
+}
+
+// bindVars binds arguments of the implementation to variables.
+func (f *FuncRuntime) bindVars(impl *FuncImpl, params []Param) {
 	if impl.argfmt != nil {
 		if impl.argfmt.Wildcard != "" {
 			f.vars[impl.argfmt.Wildcard] = Param{
@@ -227,15 +367,7 @@ func (f *FuncRuntime) bind(params []Param) (impl *FuncImpl, result *Param, resul
 				T: TypeList,
 			}
 		} else {
-			if l := len(impl.argfmt.Args); l != len(params) {
-				err = fmt.Errorf("Incorrect number of arguments to %v: expected %v, found %v", f.fi.name, l, len(params))
-				return
-			}
-			for i, arg := range impl.argfmt.Args {
-				if arg.V == nil {
-					f.vars[arg.Name] = params[i]
-				}
-			}
+			f.bindArgs(impl.argfmt.Args, params)
 		}
 	}
 	// bind to __args and _1, _2 ... variables
@@ -246,8 +378,20 @@ func (f *FuncRuntime) bind(params []Param) (impl *FuncImpl, result *Param, resul
 	for i, arg := range params {
 		f.vars[fmt.Sprintf("_%d", i+1)] = arg
 	}
-	f.args = args
-	return impl, nil, rt, types, nil
+}
+
+func (f *FuncRuntime) bindArgs(args []Arg, params []Param) {
+	for i, arg := range args {
+		if arg.Pattern != nil {
+			values, tail, _ := destructure(arg.Pattern, &params[i])
+			f.bindArgs(arg.Pattern.Args, values)
+			if tail != nil {
+				f.bindArgs([]Arg{*arg.Pattern.Tail}, []Param{*tail})
+			}
+		} else if arg.V == nil {
+			f.vars[arg.Name] = *f.fi.interpret.promote(&params[i], arg.T)
+		}
+	}
 }
 
 func (f *FuncRuntime) Eval(impl *FuncImpl) (res *Param, err error) {
@@ -273,7 +417,7 @@ L:
 				// check for tail call
 				e, forceType, err := f.lastParameter(&expr)
 				if err != nil {
-					return nil, err
+					return nil, withPos(expr.Pos, err)
 				}
 				lst, ok := e.V.(*Sexpr)
 				if !ok {
@@ -299,7 +443,7 @@ L:
 					return e, nil
 				}
 				if lst.Quoted || lst.Length() == 0 {
-					p := &Param{V: lst, T: TypeList}
+					p := &Param{V: lst, T: f.fi.interpret.literalType(lst)}
 					if forceType != nil {
 						newT, err := f.updateType(p.T, *forceType)
 						if err != nil {
@@ -325,7 +469,7 @@ L:
 				if !ok || (string(hident) != f.fi.name && string(hident) != "self") {
 					result, err := f.evalFunc(lst)
 					if err != nil {
-						return nil, err
+						return nil, withPos(expr.Pos, err)
 					}
 					if forceType != nil {
 						newT, err := f.updateType(result.T, *forceType)
@@ -360,9 +504,11 @@ L:
 					args = append(args, *arg)
 				}
 				var result *Param
+				f.tailCalls++
+				f.unshadow(0)
 				impl, result, _, _, err = f.bind(args)
 				if err != nil {
-					return nil, err
+					return nil, withPos(expr.Pos, err)
 				}
 				if result != nil {
 					return result, nil
@@ -380,9 +526,21 @@ L:
 	return &Param{V: QEmpty, T: TypeList}, nil
 }
 
-func (f *FuncRuntime) lastParameter(e *Param) (*Param, *Type, error) {
+// frame returns description of current function call for stack trace.
+func (f *FuncRuntime) frame() Frame {
+	fr := Frame{Func: f.fi.name, TailCalls: f.tailCalls}
+	if f.impl != nil && f.impl.argfmt != nil && f.fi != f.fi.interpret.main {
+		fr.Impl = f.impl.argfmt.String()
+	}
+	return fr
+}
+
+func (f *FuncRuntime) lastParameter(e *Param) (result *Param, ft *Type, err error) {
+	defer func() {
+		err = withPos(e.Pos, err)
+	}()
 	switch a := e.V.(type) {
-	case Int:
+	case Int, Float, *Rational:
 		return e, nil, nil
 	case Str:
 		return e, nil, nil
@@ -405,7 +563,7 @@ func (f *FuncRuntime) lastParameter(e *Param) (*Param, *Type, error) {
 		return result, nil, nil
 	case *Sexpr:
 		if a.Quoted {
-			return &Param{V: a, T: TypeList}, nil, nil
+			return &Param{V: a, T: f.fi.interpret.literalType(a)}, nil, nil
 		}
 		if a.Length() == 0 {
 			return nil, nil, fmt.Errorf("%v: Unexpected empty s-expression: %v", f.fi.name, a)
@@ -419,7 +577,7 @@ func (f *FuncRuntime) lastParameter(e *Param) (*Param, *Type, error) {
 				}
 				return &Param{
 					V: lm,
-					T: TypeFunc,
+					T: lm.Type(),
 				}, nil, nil
 			}
 			if name == "lambda" {
@@ -430,7 +588,7 @@ func (f *FuncRuntime) lastParameter(e *Param) (*Param, *Type, error) {
 				}
 				return &Param{
 					V: lm,
-					T: TypeFunc,
+					T: lm.Type(),
 				}, nil, nil
 			}
 			if name == "if" {
@@ -484,6 +642,7 @@ func (f *FuncRuntime) lastParameter(e *Param) (*Param, *Type, error) {
 						return nil, nil, fmt.Errorf("Cannot cast %v to %v: %v", ret.T, *retType, err)
 					}
 					ret.T = newT
+					ret = f.fi.interpret.promote(ret, newT)
 					ft = retType
 				}
 				return ret, ft, nil
@@ -532,6 +691,25 @@ func (f *FuncRuntime) lastParameter(e *Param) (*Param, *Type, error) {
 					T: TypeBool,
 				}, nil, nil
 			}
+			if name == "let" || name == "let*" {
+				return f.evalLet(a)
+			}
+			if name == "quasiquote" {
+				res, err := f.evalQuasiquote(a)
+				if err != nil {
+					return nil, nil, err
+				}
+				return res, nil, nil
+			}
+			if name == "unquote" || name == "unquote-splicing" {
+				return nil, nil, fmt.Errorf("%v outside of quasiquote", name)
+			}
+			if name == "cond" {
+				return f.evalCond(a)
+			}
+			if name == "case" {
+				return f.evalCase(a)
+			}
 			if name == "set" || name == "set'" {
 				tail, _ := a.Tail()
 				if err := f.setVar(tail.(*Sexpr) /*scoped*/, name == "set'"); err != nil {
@@ -545,7 +723,10 @@ func (f *FuncRuntime) lastParameter(e *Param) (*Param, *Type, error) {
 				if err != nil {
 					return nil, nil, err
 				}
-				return &Param{V: gen, T: TypeList}, nil, nil
+				return &Param{V: gen, T: f.fi.interpret.genType(gen.iter.ReturnType())}, nil, nil
+			}
+			if name == "try" {
+				return f.evalTry(a)
 			}
 			if name == "apply" {
 				tail, _ := a.Tail()
@@ -565,6 +746,43 @@ func (f *FuncRuntime) lastParameter(e *Param) (*Param, *Type, error) {
 	panic(fmt.Errorf("%v: Unexpected Expr type: %v (%T)", f.fi.name, e, e))
 }
 
+// (try expr (catch err handler...))
+func (f *FuncRuntime) evalTry(se *Sexpr) (*Param, *Type, error) {
+	if len(se.List) != 3 {
+		return nil, nil, fmt.Errorf("try expects expression and catch-block, found: %v", se.List[1:])
+	}
+	catch, ok := se.List[2].V.(*Sexpr)
+	if !ok || len(catch.List) < 3 || catch.List[0].V != Ident("catch") {
+		return nil, nil, fmt.Errorf("try expects (catch <var> <expr>...) as second argument, found: %v", se.List[2])
+	}
+	errVar, ok := catch.List[1].V.(Ident)
+	if !ok {
+		return nil, nil, fmt.Errorf("catch expects variable name, found: %v", catch.List[1])
+	}
+	res, err := f.tryEvalParameter(&se.List[1])
+	if err == nil {
+		return res, nil, nil
+	}
+	// error variable is visible only in the catch-block (like variables of 'let')
+	f.shadow(string(errVar), &Param{V: NewErrorValue(err), T: TypeError})
+	return f.lastBody(catch.List[2:])
+}
+
+// tryEvalParameter evaluates parameter converting panics caused by errors
+// (e.g. in lazy lists) into returned error.
+func (f *FuncRuntime) tryEvalParameter(expr *Param) (p *Param, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			e, ok := r.(error)
+			if _, rtErr := r.(runtime.Error); !ok || rtErr {
+				panic(r)
+			}
+			p, err = nil, e
+		}
+	}()
+	return f.evalParameter(expr)
+}
+
 func (f *FuncRuntime) updateType(oldT, newT Type) (Type, error) {
 	if oldT == TypeUnknown {
 		return newT, nil
@@ -573,15 +791,24 @@ func (f *FuncRuntime) updateType(oldT, newT Type) (Type, error) {
 	if err != nil {
 		return TypeUnknown, err
 	}
-	if ok {
-		return oldT, nil
+	if !ok {
+		return newT, nil
+	}
+	// canConvertType does not check type arguments (e.g. :map[any,any] is cast into :map[str,int])
+	if match, err := f.fi.interpret.matchType(newT, oldT, &map[string]Type{}); err != nil || !match {
+		return newT, nil
 	}
-	return newT, nil
+	return oldT, nil
 }
 
 func (f *FuncRuntime) evalParameter(expr *Param) (p *Param, err error) {
+	shadowed := len(f.shadowed)
 	var forceType *Type
 	defer func() {
+		// variables bound by 'let' in expr are visible only inside of it
+		if len(f.shadowed) > shadowed {
+			f.unshadow(shadowed)
+		}
 		if p != nil && forceType != nil {
 			p.T, err = f.updateType(p.T, *forceType)
 			if err != nil {
@@ -602,42 +829,146 @@ func (f *FuncRuntime) evalParameter(expr *Param) (p *Param, err error) {
 	if lst.Quoted || lst.Length() == 0 {
 		return e, nil
 	}
-	return f.evalFunc(lst)
+	p, err = f.evalFunc(lst)
+	return p, withPos(expr.Pos, err)
 }
 
 // (var-name) (value)
 func (f *FuncRuntime) setVar(se *Sexpr, scoped bool) error {
+	name, value, err := f.evalBinding("set", se)
+	if err != nil {
+		return err
+	}
+	f.vars[name] = *value
+	if scoped {
+		f.scopedVars = append(f.scopedVars, name)
+	}
+	return nil
+}
+
+// evalBinding evaluates (var-name value [:type]) form of 'set' or 'let'.
+func (f *FuncRuntime) evalBinding(form string, se *Sexpr) (string, *Param, error) {
 	if se.Length() != 2 && se.Length() != 3 {
-		return fmt.Errorf("set wants 2 or 3 arguments, found %v", se)
+		return "", nil, fmt.Errorf("%v wants 2 or 3 arguments, found %v", form, se)
 	}
 	name, ok := se.List[0].V.(Ident)
 	if !ok {
-		return fmt.Errorf("set expected identifier first, found %v", se.List[0])
+		return "", nil, fmt.Errorf("%v expected identifier first, found %v", form, se.List[0])
 	}
 	value, err := f.evalParameter(&se.List[1])
 	if err != nil {
-		return err
+		return "", nil, err
 	}
 	if se.Length() == 3 {
 		id, ok := se.List[2].V.(Ident)
 		if !ok {
-			return fmt.Errorf("%v: set expects type identifier, found: %v", f.fi.name, se.List[2])
+			return "", nil, fmt.Errorf("%v: %v expects type identifier, found: %v", f.fi.name, form, se.List[2])
 		}
 		t, err := f.fi.interpret.parseType(string(id))
 		if err != nil {
-			return err
+			return "", nil, err
 		}
 		newT, err := f.updateType(value.T, t.Expand(f.types))
 		if err != nil {
-			return fmt.Errorf("Cannot cast type %v to %v: %v", value.T, t, err)
+			return "", nil, fmt.Errorf("Cannot cast type %v to %v: %v", value.T, t, err)
 		}
 		value.T = newT
+		value = f.fi.interpret.promote(value, newT)
 	}
-	f.vars[string(name)] = *value
-	if scoped {
-		f.scopedVars = append(f.scopedVars, string(name))
+	return string(name), value, nil
+}
+
+// (let ((var1 value1) (var2 value2 :type) ...) body...)
+// Bindings are visible only in the body: they are removed by evalParameter
+// after the let-expression is evaluated or before the next tail call.
+// Values of 'let' are evaluated before any variable is bound, 'let*' binds them one by one.
+func (f *FuncRuntime) evalLet(se *Sexpr) (*Param, *Type, error) {
+	form := se.List[0].V.(Ident)
+	if len(se.List) < 3 {
+		return nil, nil, fmt.Errorf("%v: expected list of bindings and body, found: %v", form, se)
+	}
+	bindings, ok := se.List[1].V.(*Sexpr)
+	if !ok || bindings.Quoted {
+		return nil, nil, fmt.Errorf("%v: expected list of bindings, found: %v", form, se.List[1])
+	}
+	sequential := form == "let*"
+	names := make([]string, 0, len(bindings.List))
+	values := make([]*Param, 0, len(bindings.List))
+	for _, b := range bindings.List {
+		bse, ok := b.V.(*Sexpr)
+		if !ok {
+			return nil, nil, fmt.Errorf("%v: expected binding (name value), found: %v", form, b)
+		}
+		name, value, err := f.evalBinding(string(form), bse)
+		if err != nil {
+			return nil, nil, err
+		}
+		if sequential {
+			f.shadow(name, value)
+			continue
+		}
+		for _, prev := range names {
+			if prev == name {
+				return nil, nil, fmt.Errorf("%v: duplicate binding %v", form, name)
+			}
+		}
+		names = append(names, name)
+		values = append(values, value)
 	}
-	return nil
+	for i, name := range names {
+		f.shadow(name, values[i])
+	}
+	return f.lastBody(se.List[2:])
+}
+
+// lastBody evaluates statements of the body and returns the last one as lastParameter does.
+func (f *FuncRuntime) lastBody(body []Param) (*Param, *Type, error) {
+	last := len(body) - 1
+	for _, st := range body[:last] {
+		if _, err := f.evalParameter(&st); err != nil {
+			return nil, nil, err
+		}
+	}
+	return f.lastParameter(&body[last])
+}
+
+// shadow binds variable and remembers its previous value.
+func (f *FuncRuntime) shadow(name string, value *Param) {
+	f.saveVar(name)
+	f.vars[name] = *value
+}
+
+// saveVar remembers value of the variable to be restored by unshadow.
+func (f *FuncRuntime) saveVar(name string) {
+	old, ok := f.vars[name]
+	f.shadowed = append(f.shadowed, shadowedVar{name: name, value: old, ok: ok})
+}
+
+// saveArgVars remembers values of variables which are going to be bound by args.
+func (f *FuncRuntime) saveArgVars(args []Arg) {
+	for _, arg := range args {
+		if arg.Pattern != nil {
+			f.saveArgVars(arg.Pattern.Args)
+			if arg.Pattern.Tail != nil {
+				f.saveArgVars([]Arg{*arg.Pattern.Tail})
+			}
+		} else if arg.Name != "" {
+			f.saveVar(arg.Name)
+		}
+	}
+}
+
+// unshadow restores variables bound after the first n 'let' bindings.
+func (f *FuncRuntime) unshadow(n int) {
+	for i := len(f.shadowed) - 1; i >= n; i-- {
+		s := f.shadowed[i]
+		if s.ok {
+			f.vars[s.name] = s.value
+		} else {
+			delete(f.vars, s.name)
+		}
+	}
+	f.shadowed = f.shadowed[:n]
 }
 
 func (f *FuncRuntime) findVar(name string) (*Param, bool) {
@@ -650,7 +981,7 @@ func (f *FuncRuntime) findVar(name string) (*Param, bool) {
 }
 
 // (iter) (init-state)
-func (f *FuncRuntime) evalGen(se *Sexpr, hashable bool) (Expr, error) {
+func (f *FuncRuntime) evalGen(se *Sexpr, hashable bool) (*LazyList, error) {
 	if se.Length() < 2 {
 		return nil, fmt.Errorf("gen wants at least 2 arguments, found %v", se)
 	}
@@ -658,14 +989,17 @@ func (f *FuncRuntime) evalGen(se *Sexpr, hashable bool) (Expr, error) {
 	if err != nil {
 		return nil, err
 	}
-	fident, ok := fn.V.(Ident)
-	if !ok {
+	var fu Evaler
+	switch a := fn.V.(type) {
+	case Ident:
+		if fu, err = f.findFunc(string(a)); err != nil {
+			return nil, err
+		}
+	case *Lambda:
+		fu = a.fi
+	default:
 		return nil, fmt.Errorf("gen expects first argument to be a funtion, found: %v", se.List[0])
 	}
-	fu, err := f.findFunc(string(fident))
-	if err != nil {
-		return nil, err
-	}
 	var state []Param
 	for _, a := range se.List[1:] {
 		s, err := f.evalParameter(&a)
@@ -683,11 +1017,14 @@ func (f *FuncRuntime) findFunc(fname string) (result Evaler, err error) {
 		if v.T.Basic() != "func" && v.T != TypeUnknown {
 			return nil, fmt.Errorf("%v: incorrect type of '%v', expected :func, found: %v", f.fi.name, fname, v)
 		}
-		vident, ok := v.V.(Ident)
-		if !ok {
+		switch a := v.V.(type) {
+		case Ident:
+			fname = string(a)
+		case *Lambda:
+			return a.fi, nil
+		default:
 			return nil, fmt.Errorf("%v: cannot use argument %v as function", f.fi.name, v)
 		}
-		fname = string(vident)
 	}
 	fu, ok := f.fi.interpret.funcs[fname]
 	if !ok {
@@ -696,18 +1033,39 @@ func (f *FuncRuntime) findFunc(fname string) (result Evaler, err error) {
 	return fu, nil
 }
 
+// evalFuncValue evaluates expression which should return function (lambda or function name).
+func (f *FuncRuntime) evalFuncValue(expr *Param) (Evaler, error) {
+	v, err := f.evalParameter(expr)
+	if err != nil {
+		return nil, err
+	}
+	switch a := v.V.(type) {
+	case *Lambda:
+		return a.fi, nil
+	case Ident:
+		if fu, ok := f.fi.interpret.funcs[string(a)]; ok {
+			return fu, nil
+		}
+	}
+	return nil, fmt.Errorf("%v: cannot use %v as function", f.fi.name, v)
+}
+
 // (func-name) (args...)
 func (f *FuncRuntime) evalFunc(se *Sexpr) (result *Param, err error) {
 	head, err := se.Head()
 	if err != nil {
 		return nil, err
 	}
-	name, ok := head.V.(Ident)
-	if !ok {
+	var fu Evaler
+	switch name := head.V.(type) {
+	case Ident:
+		fu, err = f.findFunc(string(name))
+	case *Sexpr:
+		// call of function returned by expression, e.g. ((adder 1) 2)
+		fu, err = f.evalFuncValue(head)
+	default:
 		return nil, fmt.Errorf("Wanted identifier, found: %v (%v)", head, se)
 	}
-	fname := string(name)
-	fu, err := f.findFunc(fname)
 	if err != nil {
 		return nil, err
 	}
@@ -727,13 +1085,19 @@ func (f *FuncRuntime) evalFunc(se *Sexpr) (result *Param, err error) {
 	return result, err
 }
 
-func (f *FuncRuntime) evalLambda(se *Sexpr) (Expr, error) {
-	name := f.fi.interpret.NewLambdaName()
-	fi := NewFuncInterpret(f.fi.interpret, name)
-	body := f.replaceVars(se.List, fi)
-	fi.AddImpl(nil, body, false, TypeUnknown)
-	f.fi.interpret.funcs[name] = fi
-	return Ident(name), nil
+// (lambda body...) or (lambda (arg1:type1 arg2:type2 ...) :return-type body...)
+func (f *FuncRuntime) evalLambda(se *Sexpr) (*Lambda, error) {
+	fi := NewFuncInterpret(f.fi.interpret, f.fi.interpret.NewLambdaName())
+	if args, rt, ok := lambdaSignature(se); ok {
+		body := f.replaceVars(se.List[2:], fi)
+		if err := fi.AddImpl(args, body, false, rt); err != nil {
+			return nil, err
+		}
+	} else {
+		body := f.replaceVars(se.List, fi)
+		fi.AddImpl(nil, body, false, TypeUnknown)
+	}
+	return &Lambda{fi: fi}, nil
 }
 
 var lambdaArgRe = regexp.MustCompile(`^(_[0-9]+|__args)$`)
@@ -744,15 +1108,15 @@ func (f *FuncRuntime) replaceVars(st []Param, fi *FuncInterpret) (res []Param) {
 		case *Sexpr:
 			v := &Sexpr{Quoted: a.Quoted}
 			v.List = f.replaceVars(a.List, fi)
-			res = append(res, Param{V: v, T: s.T})
+			res = append(res, Param{V: v, T: s.T, Pos: s.Pos})
 		case Ident:
 			if lambdaArgRe.MatchString(string(a)) {
-				res = append(res, Param{V: a, T: s.T})
+				res = append(res, Param{V: a, T: s.T, Pos: s.Pos})
 			} else if v, ok := f.findVar(string(a)); ok {
 				fi.AddVar(string(a), v)
-				res = append(res, Param{V: a, T: s.T})
+				res = append(res, Param{V: a, T: s.T, Pos: s.Pos})
 			} else {
-				res = append(res, Param{V: a, T: s.T})
+				res = append(res, Param{V: a, T: s.T, Pos: s.Pos})
 			}
 		default:
 			res = append(res, s)
@@ -797,20 +1161,33 @@ func (f *FuncInterpret) matchParameters(argfmt *ArgFmt, params []Param) (result
 
 	binds := map[string]Expr{}
 	typeBinds := map[string]Type{}
-	if len(argfmt.Args) != len(params) {
+	if !f.matchArgs(argfmt.Args, params, binds, typeBinds) {
 		return false, nil
 	}
-	for i, arg := range argfmt.Args {
+	return true, typeBinds
+}
+
+func (f *FuncInterpret) matchArgs(args []Arg, params []Param, binds map[string]Expr, typeBinds map[string]Type) bool {
+	if len(args) != len(params) {
+		return false
+	}
+	for i, arg := range args {
 		param := params[i]
 		match, err := f.interpret.matchType(arg.T, param.T, &typeBinds)
 		if err != nil {
-			return false, nil
+			return false
 		}
 		if !match {
-			return false, nil
+			return false
 		}
 		if !f.matchValue(&arg, &param) {
-			return false, nil
+			return false
+		}
+		if arg.Pattern != nil {
+			if !f.matchPattern(arg.Pattern, &param, binds, typeBinds) {
+				return false
+			}
+			continue
 		}
 		if arg.Name == "" {
 			continue
@@ -820,12 +1197,64 @@ func (f *FuncInterpret) matchParameters(argfmt *ArgFmt, params []Param) (result
 		}
 		if binded, ok := binds[arg.Name]; ok {
 			if !Equal(binded, param.V) {
-				return false, nil
+				return false
 			}
 		}
 		binds[arg.Name] = param.V
 	}
-	return true, typeBinds
+	return true
+}
+
+// matchPattern checks that parameter is a value of the specified variant (or a list) and matches its fields (or elements).
+func (f *FuncInterpret) matchPattern(pattern *Pattern, p *Param, binds map[string]Expr, typeBinds map[string]Type) bool {
+	if p.V == nil {
+		// not a real parameter, just a Type binder
+		return true
+	}
+	values, tail, ok := destructure(pattern, p)
+	if !ok || !f.matchArgs(pattern.Args, values, binds, typeBinds) {
+		return false
+	}
+	if tail == nil {
+		return true
+	}
+	return f.matchArgs([]Arg{*pattern.Tail}, []Param{*tail}, binds, typeBinds)
+}
+
+// destructure returns fields of the variant or elements of the list (and the rest of the list) matched by pattern.
+func destructure(pattern *Pattern, p *Param) (values []Param, tail *Param, ok bool) {
+	if pattern.Variant != "" {
+		r, ok := p.V.(*Record)
+		if !ok || r.def.name != pattern.Variant {
+			return nil, nil, false
+		}
+		return r.values, nil, true
+	}
+	l, ok := p.V.(List)
+	if !ok {
+		return nil, nil, false
+	}
+	for range pattern.Args {
+		if l.Empty() {
+			return nil, nil, false
+		}
+		h, err := l.Head()
+		if err != nil {
+			return nil, nil, false
+		}
+		values = append(values, *h)
+		if l, err = l.Tail(); err != nil {
+			return nil, nil, false
+		}
+	}
+	if pattern.Tail == nil {
+		return values, nil, l.Empty()
+	}
+	t := p.T
+	if t == TypeUnknown {
+		t = TypeList
+	}
+	return values, &Param{V: l, T: t, Pos: p.Pos}, true
 }
 
 func (f *FuncInterpret) matchValue(a *Arg, p *Param) bool {
@@ -877,8 +1306,8 @@ func (f *FuncRuntime) cleanup() {
 	for _, varname := range f.scopedVars {
 		expr := f.vars[varname]
 		switch a := expr.V.(type) {
-		case Ident:
-			f.fi.interpret.DeleteLambda(string(a))
+		case *Lambda:
+			// lambdas are garbage collected
 		case io.Closer:
 			if err := a.Close(); err != nil {
 				fmt.Fprintf(os.Stderr, "Close() failed: %v\n", err)
@@ -891,35 +1320,44 @@ func (f *FuncRuntime) cleanup() {
 }
 
 func (i *Interpret) matchType(arg Type, val Type, typeBinds *map[string]Type) (result bool, eerroorr error) {
-	arg = i.UnaliasType(arg)
-	val = i.UnaliasType(val)
+	return i.matchTypeExpr(i.typeExpr(arg), i.typeExpr(val), typeBinds)
+}
 
-	if i.IsContract(arg) {
-		if bind, ok := (*typeBinds)[arg.Basic()]; ok && string(bind) != strings.TrimLeft(string(val), ":") {
-			return false, nil
+func (i *Interpret) matchTypeExpr(arg, val *TypeExpr, typeBinds *map[string]Type) (bool, error) {
+	arg = i.unaliasExpr(arg)
+	val = i.unaliasExpr(val)
+
+	if i.isTypeVar(arg) {
+		if i.isTypeVar(val) {
+			// e.g. generic function passed as argument: its type variables are not bound yet
+			return true, nil
+		}
+		v := val.Type()
+		bind, ok := (*typeBinds)[arg.Name]
+		if ok && !noTypeInfo(bind) {
+			// e.g. element type of empty list does not restrict type variable
+			// :list[int] may be used where :list[any] is bound
+			return noTypeInfo(v) || bind == v || i.covariantType(v, bind), nil
 		}
-		(*typeBinds)[arg.Basic()] = Type(strings.TrimLeft(string(val), ":"))
+		(*typeBinds)[arg.Name] = v
 		return true, nil
 	}
-	if val == TypeUnknown || arg == TypeUnknown {
+	if len(val.Args) == 0 && noTypeInfo(Type(val.Name)) || len(arg.Args) == 0 && Type(arg.Name) == TypeUnknown {
 		return true, nil
 	}
-	if (arg == TypeFunc && val.Basic() == "func") || (val == TypeFunc && arg.Basic() == "func") {
+	if arg.Name == "func" && val.Name == "func" && (len(arg.Args) == 0 || len(val.Args) == 0) {
 		return true, nil
 	}
 
-	parent, err := i.toParent(val, Type(arg.Basic()))
+	parent, err := i.toParentExpr(val, arg.Name)
 	if err != nil {
 		return false, err
 	}
-
-	aParams := arg.Arguments()
-	vParams := parent.Arguments()
-	if len(aParams) != len(vParams) {
+	if len(arg.Args) != len(parent.Args) {
 		return false, nil
 	}
-	for j, p := range aParams {
-		ok, err := i.matchType(Type(p), Type(vParams[j]), typeBinds)
+	for j, p := range arg.Args {
+		ok, err := i.matchTypeExpr(p, parent.Args[j], typeBinds)
 		if err != nil || !ok {
 			return false, err
 		}
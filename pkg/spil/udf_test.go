@@ -1,8 +1,10 @@
-package main
+package spil
 
 import (
 	"fmt"
+	"io/ioutil"
 	"os"
+	"strings"
 	"testing"
 )
 
@@ -224,6 +226,30 @@ func TestCanConvertType(t *testing.T) {
 	}
 }
 
+func TestUpdateType(t *testing.T) {
+	tests := []struct {
+		oldT, newT, exp Type
+	}{
+		{TypeUnknown, TypeInt, TypeInt},
+		{TypeInt, TypeFloat, TypeInt},
+		{TypeList, "list[int]", "list[int]"},
+		{"map[any,any]", "map[str,int]", "map[str,int]"},
+	}
+	in := NewInterpreter(os.Stderr, getTestLibraryDir())
+	f := NewFuncRuntime(NewFuncInterpret(in, "test"))
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("%v->%v", test.oldT, test.newT), func(t *testing.T) {
+			act, err := f.updateType(test.oldT, test.newT)
+			if err != nil {
+				t.Fatalf("updateType() failed: %v", err)
+			}
+			if act != test.exp {
+				t.Errorf("updateType(%v, %v) failed: expected %v, actual %v", test.oldT, test.newT, test.exp, act)
+			}
+		})
+	}
+}
+
 func TestMatchType(t *testing.T) {
 	tests := []struct {
 		name   string
@@ -254,6 +280,11 @@ func TestMatchType(t *testing.T) {
 		{"list[a]-list[any]", "list[a]", "list[any]", &map[string]Type{}, true},
 		{"func[a]-func", "func[a]", "func", &map[string]Type{}, true},
 		{"func-func[a]", "func", "func[a]", &map[string]Type{}, true},
+		{"map[str,list[a]]-map[str,list[int]]", "map[str,list[a]]", "map[str,list[int]]", &map[string]Type{}, true},
+		{"map[str,list[a]]-map[str,list[str]]", "map[str,list[a]]", "map[str,list[str]]", &map[string]Type{"a": "int"}, false},
+		{"list[list[a]]-tset[tset[int]]", "list[list[a]]", "tset[tset[int]]", &map[string]Type{}, true},
+		{"func[func[a,b],list[a],list[b]]-func[func[int,str],list[int],list[str]]", "func[func[a,b],list[a],list[b]]", "func[func[int,str],list[int],list[str]]", &map[string]Type{}, true},
+		{"func[func[a,b],list[a],list[b]]-func[func[int,str],list[int],list[int]]", "func[func[a,b],list[a],list[b]]", "func[func[int,str],list[int],list[int]]", &map[string]Type{}, false},
 	}
 
 	in := NewInterpreter(os.Stderr, getTestLibraryDir())
@@ -313,3 +344,99 @@ func TestToParent(t *testing.T) {
 		})
 	}
 }
+
+func TestMatchListPatterns(t *testing.T) {
+	tests := []struct {
+		argfmt string
+		value  string
+		exp    bool
+		binds  string
+	}{
+		{"((h . t))", "'(1 2 3)", true, "h=1 t='(2 3)"},
+		{"((h . t))", "'(1)", true, "h=1 t='()"},
+		{"((h . t))", "'()", false, ""},
+		{"((a b))", "'(1 2)", true, "a=1 b=2"},
+		{"((a b))", "'(1 2 3)", false, ""},
+		{"((a b . t))", "'(1 2 3)", true, "a=1 b=2 t='(3)"},
+		{"((0 . t))", "'(1 2)", false, ""},
+		{"((x x))", "'(5 5)", true, "x=5"},
+		{"((x x))", "'(5 6)", false, ""},
+		{"(((a b) . t))", "'((1 2) 3)", true, "a=1 b=2 t='(3)"},
+		{"(((a b) . t))", "'(1 2 3)", false, ""},
+		{"((c . t))", `"hi"`, true, "c=h t=i"},
+	}
+	in := NewInterpreter(os.Stderr, getTestLibraryDir())
+	fi := NewFuncInterpret(in, "__test__")
+	for _, test := range tests {
+		t.Run(test.argfmt+" "+test.value, func(t *testing.T) {
+			parser := NewParser(strings.NewReader(test.argfmt+" "+test.value), in)
+			af, _ := parser.NextExpr()
+			value, _ := parser.NextExpr()
+			if err := fi.AddImpl(af.V, []Param{{V: QEmpty, T: TypeList}}, false, TypeUnknown); err != nil {
+				t.Fatalf("AddImpl() failed: %v", err)
+			}
+			impl := fi.bodies[len(fi.bodies)-1]
+			args := []Param{*value}
+			act, _ := fi.matchParameters(impl.argfmt, args)
+			if act != test.exp {
+				t.Fatalf("Incorrect matchParameters(%v, %v): expected %v, actual %v", impl.argfmt, test.value, test.exp, act)
+			}
+			if !act {
+				return
+			}
+			run := NewFuncRuntime(fi)
+			run.bindArgs(impl.argfmt.Args, args)
+			var binds []string
+			for _, name := range []string{"h", "a", "b", "c", "x", "t"} {
+				if v, ok := run.vars[name]; ok {
+					b := &strings.Builder{}
+					v.V.Print(b)
+					binds = append(binds, name+"="+b.String())
+				}
+			}
+			if act := strings.Join(binds, " "); act != test.binds {
+				t.Errorf("Incorrect binds: expected %q, actual %q", test.binds, act)
+			}
+		})
+	}
+}
+
+func TestListPatternTypes(t *testing.T) {
+	const program = `(def inc-head ((h . t)) (+ h 1))
+(def inc-head ('()) 0)
+(print (inc-head (do '("a") :list[str])))
+`
+	in := NewInterpreter(ioutil.Discard, getTestLibraryDir())
+	if err := in.Parse("test.lisp", strings.NewReader(program)); err != nil {
+		t.Fatalf("Parse() failed: %v", err)
+	}
+	errs := in.Check()
+	// h gets element type of the argument :list[str]
+	exp := "inc-head: +: Expected all numeric arguments"
+	if len(errs) != 1 || !strings.Contains(errs[0].Error(), exp) {
+		t.Errorf("Incorrect Check() result: expected %q, actual %v", exp, errs)
+	}
+}
+
+func TestGuards(t *testing.T) {
+	tests := []programTest{
+		{"fallthrough", "(def f (n:int) :str when (< n 0) \"neg\")\n(def f (n:int) :str \"pos\")\n(print (f -1) (f 1))", "neg pos\n", ""},
+		{"bound vars", "(def f ((h . t)) :int when (= h (native.length t)) 1)\n(def f (l) :int 0)\n(print (f '(2 a b)) (f '(1 a b)))", "1 0\n", ""},
+		{"no match", "(def f (n:int) :str when (< n 0) \"neg\")\n(print (f 1))", "", "f: no matching function implementation found"},
+		{"not bool", "(def f (n:int) :int when (+ n 1) n)", "", "f: guard should be :bool, found: :int"},
+	}
+	checkPrograms(t, tests)
+}
+
+func TestLet(t *testing.T) {
+	tests := []programTest{
+		{"scope", "(set x 1)\n(print (let ((x 2) (y x)) (+ x y)) x)", "3 1\n", ""},
+		{"sequential", "(print (let* ((x 2) (y x)) (+ x y)))", "4\n", ""},
+		{"set in body", "(def f () :int (let ((x 2)) (set y x) (+ x y)))\n(print (f))", "4\n", ""},
+		{"tail call", "(def f (n:int) :int (if (= n 0) 0 (let ((m (- n 1))) (f m))))\n(print (f 100000))", "0\n", ""},
+		{"not visible", "(def f () :int (do (let ((x 1)) x) x))", "", "Undefined variable: x"},
+		{"type", "(def f () :str (let ((x 1)) x))", "", "expected :str actual :int"},
+		{"duplicate", "(print (let ((x 1) (x 2)) x))", "", "duplicate binding x"},
+	}
+	checkPrograms(t, tests)
+}
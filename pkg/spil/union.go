@@ -0,0 +1,344 @@
+package spil
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// (defunion :name (:variant1 field1:type1 ...) (:variant2 ...) :variant3)
+// defines algebraic data type :name and constructor functions (variant1 value1 ...) for each variant.
+// Variants can be used as patterns in function arguments: (def f ((:variant1 x)) ...)
+func (in *Interpret) defineUnion(args []Param) error {
+	if len(args) < 2 {
+		return fmt.Errorf("'defunion' expected type and list of variants, found: %v", args)
+	}
+	id, ok := args[0].V.(Ident)
+	if !ok {
+		return fmt.Errorf("defunion expects first argument to be new type, found: %v", args[0])
+	}
+	name, ok := ParseType(string(id))
+	if !ok || len(name.Arguments()) > 0 {
+		return fmt.Errorf("defunion expects first argument to be new type, found: %v", args[0])
+	}
+	if _, ok := in.types[name]; ok {
+		return fmt.Errorf("Cannot redefine type %v", name)
+	}
+	in.types[name] = TypeAny
+	defs, err := in.parseVariants(name, args[1:])
+	if err != nil {
+		delete(in.types, name)
+		return err
+	}
+	funcs := map[string]Evaler{}
+	for _, def := range defs {
+		def := def
+		cons := string(def.name)
+		funcs[cons] = TypedEvalerFunc(cons, func(args []Param) (*Param, error) {
+			return in.FMakeRecord(def, args)
+		}, func(params []Param) (Type, error) {
+			return in.MakeRecordArgs(def, params)
+		}, name)
+	}
+	if err := in.addFuncs(funcs); err != nil {
+		delete(in.types, name)
+		return fmt.Errorf("Cannot define union %v: %w", name, err)
+	}
+	for _, def := range defs {
+		in.variants[def.name] = def
+	}
+	in.unions[name] = defs
+	return nil
+}
+
+func (in *Interpret) parseVariants(name Type, args []Param) ([]*recordDef, error) {
+	var defs []*recordDef
+	for _, arg := range args {
+		var id Ident
+		var fields []Param
+		switch a := arg.V.(type) {
+		case Ident:
+			id = a
+		case *Sexpr:
+			if a.Empty() {
+				return nil, fmt.Errorf("defunion %v: empty variant", name)
+			}
+			id, _ = a.List[0].V.(Ident)
+			fields = a.List[1:]
+		}
+		variant, ok := ParseType(string(id))
+		if !ok || len(variant.Arguments()) > 0 {
+			return nil, fmt.Errorf("defunion %v: expected variant (:name field1 field2 ...), found %v", name, arg)
+		}
+		if _, ok := in.types[variant]; ok {
+			return nil, fmt.Errorf("defunion %v: variant %v conflicts with existing type", name, variant)
+		}
+		if _, ok := in.variants[variant]; ok {
+			return nil, fmt.Errorf("defunion %v: variant %v is already defined", name, variant)
+		}
+		for _, def := range defs {
+			if def.name == variant {
+				return nil, fmt.Errorf("defunion %v: duplicate variant %v", name, variant)
+			}
+		}
+		def := &recordDef{name: variant, union: name}
+		var err error
+		if def.fields, err = in.parseFields("defunion", variant, fields); err != nil {
+			return nil, err
+		}
+		defs = append(defs, def)
+	}
+	return defs, nil
+}
+
+// resolvePatterns checks variants used in patterns and sets types of pattern arguments.
+func (in *Interpret) resolvePatterns(args []Arg) error {
+	for i := range args {
+		p := args[i].Pattern
+		if p == nil {
+			continue
+		}
+		if p.Variant == "" {
+			if err := in.resolveListPattern(&args[i]); err != nil {
+				return err
+			}
+			continue
+		}
+		def, ok := in.variants[p.Variant]
+		if !ok {
+			return fmt.Errorf("unknown variant %v in pattern", p.Variant)
+		}
+		if len(p.Args) != len(def.fields) {
+			return fmt.Errorf("variant %v has %v fields, found %v in pattern", p.Variant, len(def.fields), len(p.Args))
+		}
+		args[i].T = def.union
+		for j := range p.Args {
+			if sub := &p.Args[j]; sub.V == nil && sub.T == TypeUnknown {
+				sub.T = def.fields[j].T
+			}
+		}
+		if err := in.resolvePatterns(p.Args); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// resolveListPattern sets type of list pattern (head . tail:list[a]) to the type of its tail
+// and element type of the list flows into untyped elements.
+func (in *Interpret) resolveListPattern(arg *Arg) error {
+	p := arg.Pattern
+	arg.T = TypeList
+	if p.Tail != nil && p.Tail.T != TypeUnknown {
+		t, err := in.parseType(p.Tail.T.String())
+		if err != nil {
+			return err
+		}
+		elem, err := in.elemType(t, TypeList)
+		if err != nil {
+			return fmt.Errorf("incorrect type of list pattern tail: %w", err)
+		}
+		arg.T = p.Tail.T
+		for j := range p.Args {
+			if sub := &p.Args[j]; sub.V == nil && sub.Pattern == nil && sub.T == TypeUnknown && elem != TypeUnknown {
+				sub.T = elem
+			}
+		}
+	} else if p.Tail != nil {
+		p.Tail.T = arg.T
+	}
+	return in.resolvePatterns(p.Args)
+}
+
+// listPatternTypes sets types of untyped variables of list pattern p
+// which matches value of type t, e.g. h and t of (h . t) get :int and :list[int] for :list[int].
+func (in *Interpret) listPatternTypes(values map[string]Type, p *Pattern, t Type) {
+	elem, err := in.elemType(t, TypeList)
+	if err != nil || elem == TypeUnknown || elem == TypeAny {
+		return
+	}
+	for _, arg := range p.Args {
+		switch {
+		case arg.Pattern != nil && arg.Pattern.Variant == "":
+			in.listPatternTypes(values, arg.Pattern, elem)
+		case arg.Pattern == nil && arg.V == nil && arg.Name != "" && arg.T == TypeUnknown:
+			values[arg.Name] = elem
+		}
+	}
+	if p.Tail != nil && p.Tail.T == TypeList {
+		// tail without type annotation
+		values[p.Tail.Name] = t
+	}
+}
+
+// checkPatterns finds functions which implementations do not cover all variants of algebraic data types
+// or do not cover both empty and non-empty lists in list patterns.
+func (in *Interpret) checkPatterns() (errs []error) {
+	names := make([]string, 0, len(in.funcs))
+	for name, fn := range in.funcs {
+		if _, ok := fn.(*FuncInterpret); ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fi := in.funcs[name].(*FuncInterpret)
+		if fi.native != nil {
+			// builtin function matches the rest of arguments
+			continue
+		}
+		rows := map[int][][]Arg{}
+		// position of the first implementation with patterns for every number of arguments
+		positions := map[int]*Pos{}
+		var arities []int
+		wildcard := false
+		for _, impl := range fi.bodies {
+			if impl.guard != nil {
+				// implementation with guard may not match
+				continue
+			}
+			if impl.argfmt == nil || impl.argfmt.Wildcard != "" {
+				wildcard = true
+				continue
+			}
+			n := len(impl.argfmt.Args)
+			rows[n] = append(rows[n], impl.argfmt.Args)
+			if _, ok := positions[n]; !ok && hasPatterns(impl.argfmt.Args) {
+				positions[n] = impl.pos
+				arities = append(arities, n)
+			}
+		}
+		if wildcard {
+			continue
+		}
+		for _, n := range arities {
+			if missing, ok := in.missingPattern(rows[n], n); !ok {
+				err := fmt.Errorf("%v: implementations do not cover all variants, e.g. (%v) is not matched", fi.name, strings.Join(missing, " "))
+				errs = append(errs, withPos(positions[n], err))
+			}
+		}
+	}
+	return errs
+}
+
+func hasPatterns(args []Arg) bool {
+	for _, arg := range args {
+		if arg.Pattern != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// missingPattern checks if rows of patterns with n columns match all possible values.
+// If they do not then example of unmatched arguments is returned.
+func (in *Interpret) missingPattern(rows [][]Arg, n int) ([]string, bool) {
+	if n == 0 {
+		return []string{}, len(rows) > 0
+	}
+	var union Type
+	for _, row := range rows {
+		if p := row[0].Pattern; p != nil && p.Variant != "" {
+			union = in.variants[p.Variant].union
+			break
+		}
+	}
+	if union == "" && isListColumn(rows) {
+		return in.missingListPattern(rows, n)
+	}
+	if union == "" {
+		// literal values match only themselves, other arguments match everything
+		var rest [][]Arg
+		for _, row := range rows {
+			if row[0].V == nil {
+				rest = append(rest, row[1:])
+			}
+		}
+		if missing, ok := in.missingPattern(rest, n-1); !ok {
+			return append([]string{"_"}, missing...), false
+		}
+		return nil, true
+	}
+	for _, def := range in.unions[union] {
+		k := len(def.fields)
+		var spec [][]Arg
+		for _, row := range rows {
+			switch p := row[0].Pattern; {
+			case p != nil && p.Variant == def.name:
+				spec = append(spec, append(append([]Arg(nil), p.Args...), row[1:]...))
+			case p == nil && row[0].V == nil:
+				spec = append(spec, append(make([]Arg, k), row[1:]...))
+			}
+		}
+		if missing, ok := in.missingPattern(spec, k+n-1); !ok {
+			pattern := "(" + def.name.String()
+			for _, m := range missing[:k] {
+				pattern += " " + m
+			}
+			pattern += ")"
+			return append([]string{pattern}, missing[k:]...), false
+		}
+	}
+	return nil, true
+}
+
+// isListColumn checks if the first column of rows contains list patterns or '().
+func isListColumn(rows [][]Arg) bool {
+	for _, row := range rows {
+		if p := row[0].Pattern; p != nil && p.Variant == "" {
+			return true
+		}
+		if l, ok := row[0].V.(*Sexpr); ok && l.Empty() {
+			return true
+		}
+	}
+	return false
+}
+
+// missingListPattern checks rows which first column matches lists:
+// both '() and (head . tail) should be matched.
+func (in *Interpret) missingListPattern(rows [][]Arg, n int) ([]string, bool) {
+	var empty, cons [][]Arg
+	for _, row := range rows {
+		arg := row[0]
+		switch p := arg.Pattern; {
+		case p != nil:
+			// (a b . t) is (a . (b . t)), (a b) is (a . (b . '()))
+			var tail Arg
+			switch {
+			case len(p.Args) > 1:
+				tail = Arg{T: TypeList, Pattern: &Pattern{Args: p.Args[1:], Tail: p.Tail}}
+			case p.Tail != nil:
+				tail = *p.Tail
+			default:
+				tail = Arg{T: TypeList, V: QEmpty}
+			}
+			cons = append(cons, append([]Arg{p.Args[0], tail}, row[1:]...))
+		case arg.V == nil:
+			empty = append(empty, row[1:])
+			cons = append(cons, append(make([]Arg, 2), row[1:]...))
+		default:
+			if l, ok := arg.V.(*Sexpr); ok && l.Empty() {
+				empty = append(empty, row[1:])
+			}
+		}
+	}
+	if missing, ok := in.missingPattern(empty, n-1); !ok {
+		return append([]string{"'()"}, missing...), false
+	}
+	if missing, ok := in.missingPattern(cons, n+1); !ok {
+		return append([]string{consPattern(missing[0], missing[1])}, missing[2:]...), false
+	}
+	return nil, true
+}
+
+// consPattern returns list pattern with head h and tail t: "(h . t)", "(h)" or "(h a b . c)".
+func consPattern(h, t string) string {
+	switch {
+	case t == "'()":
+		return "(" + h + ")"
+	case strings.HasPrefix(t, "(") && !strings.HasPrefix(t, "(:"):
+		return "(" + h + " " + t[1:]
+	}
+	return "(" + h + " . " + t + ")"
+}
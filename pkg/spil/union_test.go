@@ -0,0 +1,79 @@
+package spil
+
+import (
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+func TestExhaustivePatterns(t *testing.T) {
+	const unions = `(defunion :result (:ok value:int) (:err message:str))
+(defunion :expr (:num n:int) (:add left:expr right:expr))
+`
+	tests := []struct {
+		name  string
+		input string
+		exp   string
+	}{
+		{"all variants", "(def f ((:ok v)) v)\n(def f ((:err m)) 0)", ""},
+		{"catch-all", "(def f ((:ok v)) v)\n(def f (x) 0)", ""},
+		{"missing variant", "(def f ((:ok v)) v)", "f: implementations do not cover all variants, e.g. ((:err _)) is not matched"},
+		{"literal field", "(def f ((:ok 0)) 0)\n(def f ((:err m)) 0)", "e.g. ((:ok _)) is not matched"},
+		{"nested", "(def f ((:num n)) n)\n(def f ((:add (:num n) r)) n)", "e.g. ((:add (:add _ _) _)) is not matched"},
+		{"two arguments", "(def f ((:ok a) (:ok b)) 0)\n(def f ((:err m) x) 0)\n(def f (x (:err m)) 0)", ""},
+		{"two arguments missing", "(def f ((:ok a) (:ok b)) 0)\n(def f ((:err m) x) 0)", "e.g. ((:ok _) (:err _)) is not matched"},
+		{"literal argument", "(def f (0 (:ok a)) 0)\n(def f (n (:err m)) 0)", "e.g. (_ (:ok _)) is not matched"},
+		{"list patterns", "(def f ('()) 0)\n(def f ((h . t)) h)", ""},
+		{"missing empty list", "(def f ((h . t)) h)", "e.g. ('()) is not matched"},
+		{"missing non-empty list", "(def f ('()) 0)\n(def f ((a)) a)", "e.g. ((_ _ . _)) is not matched"},
+		{"list lengths", "(def f ('()) 0)\n(def f ((a)) a)\n(def f ((a b . t)) a)", ""},
+		{"variants in list", "(def f ('()) 0)\n(def f (((:ok v) . t)) v)", "e.g. (((:err _) . _)) is not matched"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			in := NewInterpreter(ioutil.Discard, getTestLibraryDir())
+			if err := in.Parse("test.lisp", strings.NewReader(unions+test.input)); err != nil {
+				t.Fatalf("Parse() failed: %v", err)
+			}
+			if errs := in.Check(); len(errs) > 0 {
+				t.Fatalf("Check() failed: %v", errs)
+			}
+			warns := in.Warnings()
+			if test.exp == "" {
+				if len(warns) > 0 {
+					t.Errorf("Unexpected warnings: %v", warns)
+				}
+				return
+			}
+			if len(warns) != 1 || !strings.Contains(warns[0].Error(), test.exp) {
+				t.Errorf("Incorrect warnings: expected %q, actual %v", test.exp, warns)
+			}
+		})
+	}
+}
+
+func TestDefineUnionErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		exp   string
+	}{
+		{"no variants", "(defunion :result)", "expected type and list of variants"},
+		{"duplicate variant", "(defunion :result (:ok v) (:ok w))", "duplicate variant :ok"},
+		{"variant is type", "(defunion :result (:int v))", "variant :int conflicts with existing type"},
+		{"unknown variant", "(def f ((:ok v)) v)", "unknown variant :ok in pattern"},
+		{"fields number", "(defunion :result (:ok v))\n(def f ((:ok v w)) v)", "variant :ok has 1 fields, found 2 in pattern"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			in := NewInterpreter(ioutil.Discard, getTestLibraryDir())
+			err := in.Parse("test.lisp", strings.NewReader(test.input))
+			if err == nil {
+				t.Fatalf("Error expected")
+			}
+			if !strings.Contains(err.Error(), test.exp) {
+				t.Errorf("Incorrect error: expected %q, actual %q", test.exp, err.Error())
+			}
+		})
+	}
+}
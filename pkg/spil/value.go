@@ -0,0 +1,210 @@
+package spil
+
+import (
+	"fmt"
+	"math/big"
+	"reflect"
+)
+
+// Conversion between Go values and spil values.
+
+// ValueOf converts Go value into spil parameter.
+// Supported types are: integers, *big.Int, floats, *big.Rat, string, bool, slices and maps of supported types,
+// sets (map[interface{}]struct{}) and Param itself.
+func (in *Interpret) ValueOf(v interface{}) (Param, error) {
+	switch a := v.(type) {
+	case Param:
+		return a, nil
+	case *Param:
+		return *a, nil
+	case Expr:
+		return Param{V: a, T: a.Type()}, nil
+	case int:
+		return Param{V: in.intMaker.MakeInt(int64(a)), T: TypeInt}, nil
+	case int32:
+		return Param{V: in.intMaker.MakeInt(int64(a)), T: TypeInt}, nil
+	case int64:
+		return Param{V: in.intMaker.MakeInt(a), T: TypeInt}, nil
+	case *big.Int:
+		if a.IsInt64() {
+			return Param{V: in.intMaker.MakeInt(a.Int64()), T: TypeInt}, nil
+		}
+		_, bigmath := in.intMaker.(*BigIntMaker)
+		return Param{V: &BigInt{value: new(big.Int).Set(a), auto: !bigmath}, T: TypeInt}, nil
+	case *big.Rat:
+		_, bigmath := in.intMaker.(*BigIntMaker)
+		return Param{V: &Rational{value: new(big.Rat).Set(a), big: bigmath}, T: TypeRational}, nil
+	case float64:
+		return Param{V: Float(a), T: TypeFloat}, nil
+	case float32:
+		return Param{V: Float(a), T: TypeFloat}, nil
+	case string:
+		return Param{V: Str(a), T: TypeStr}, nil
+	case bool:
+		return Param{V: Bool(a), T: TypeBool}, nil
+	case []interface{}:
+		res := &Sexpr{Quoted: true}
+		for _, item := range a {
+			p, err := in.ValueOf(item)
+			if err != nil {
+				return Param{}, err
+			}
+			res.List = append(res.List, p)
+		}
+		return Param{V: res, T: TypeList}, nil
+	case map[interface{}]interface{}:
+		args := make([]Param, 0, 2*len(a))
+		for k, v := range a {
+			for _, item := range []interface{}{k, v} {
+				p, err := in.ValueOf(item)
+				if err != nil {
+					return Param{}, err
+				}
+				args = append(args, p)
+			}
+		}
+		res, err := in.FMapOf(args)
+		if err != nil {
+			return Param{}, err
+		}
+		return *res, nil
+	case map[interface{}]struct{}:
+		args := make([]Param, 0, len(a))
+		for item := range a {
+			p, err := in.ValueOf(item)
+			if err != nil {
+				return Param{}, err
+			}
+			args = append(args, p)
+		}
+		res, err := in.FSetOf(args)
+		if err != nil {
+			return Param{}, err
+		}
+		return *res, nil
+	case []int:
+		res := &Sexpr{Quoted: true}
+		for _, item := range a {
+			res.List = append(res.List, Param{V: in.intMaker.MakeInt(int64(item)), T: TypeInt})
+		}
+		return Param{V: res, T: TypeList}, nil
+	case []string:
+		res := &Sexpr{Quoted: true}
+		for _, item := range a {
+			res.List = append(res.List, Param{V: Str(item), T: TypeStr})
+		}
+		return Param{V: res, T: TypeList}, nil
+	}
+	return Param{}, fmt.Errorf("ValueOf: unsupported Go type %T", v)
+}
+
+// GoValue converts spil value into Go value:
+// Int into int64 (or *big.Int if it does not fit), Float into float64, Rational into *big.Rat,
+// Str into string, Bool into bool,
+// lists into []interface{}, maps into map[interface{}]interface{}, sets into map[interface{}]struct{}.
+func GoValue(e Expr) (interface{}, error) {
+	switch a := e.(type) {
+	case Int64:
+		return int64(a), nil
+	case *BigInt:
+		if a.value.IsInt64() {
+			return a.value.Int64(), nil
+		}
+		return new(big.Int).Set(a.value), nil
+	case Float:
+		return float64(a), nil
+	case *Rational:
+		return new(big.Rat).Set(a.value), nil
+	case Str:
+		return string(a), nil
+	case Bool:
+		return bool(a), nil
+	case Ident:
+		return string(a), nil
+	case *Map:
+		res := map[interface{}]interface{}{}
+		err := a.each(func(key, value *Param) error {
+			k, err := GoValue(key.V)
+			if err != nil {
+				return err
+			}
+			if !reflect.TypeOf(k).Comparable() {
+				return fmt.Errorf("GoValue: unsupported map key %v", key.V)
+			}
+			v, err := GoValue(value.V)
+			if err != nil {
+				return err
+			}
+			res[k] = v
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		return res, nil
+	case *Set:
+		res := map[interface{}]struct{}{}
+		err := a.each(func(p *Param) error {
+			v, err := GoValue(p.V)
+			if err != nil {
+				return err
+			}
+			if !reflect.TypeOf(v).Comparable() {
+				return fmt.Errorf("GoValue: unsupported set element %v", p.V)
+			}
+			res[v] = struct{}{}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		return res, nil
+	case List:
+		res := []interface{}{}
+		var l List = a
+		for !l.Empty() {
+			h, err := l.Head()
+			if err != nil {
+				return nil, err
+			}
+			v, err := GoValue(h.V)
+			if err != nil {
+				return nil, err
+			}
+			res = append(res, v)
+			l, err = l.Tail()
+			if err != nil {
+				return nil, err
+			}
+		}
+		return res, nil
+	}
+	return nil, fmt.Errorf("GoValue: unsupported value %v (%T)", e, e)
+}
+
+// Call calls function with specified name and arguments.
+// Arguments are converted from Go values with ValueOf.
+func (in *Interpret) Call(fname string, args ...interface{}) (*Param, error) {
+	fn, ok := in.funcs[fname]
+	if !ok {
+		return nil, fmt.Errorf("Unknown function: %v", fname)
+	}
+	params := make([]Param, 0, len(args))
+	for i, arg := range args {
+		p, err := in.ValueOf(arg)
+		if err != nil {
+			return nil, fmt.Errorf("%v: cannot convert argument %v: %w", fname, i, err)
+		}
+		params = append(params, p)
+	}
+	return fn.Eval(params)
+}
+
+// CallValue calls function with specified name and converts result into Go value.
+func (in *Interpret) CallValue(fname string, args ...interface{}) (interface{}, error) {
+	res, err := in.Call(fname, args...)
+	if err != nil {
+		return nil, err
+	}
+	return GoValue(res.V)
+}
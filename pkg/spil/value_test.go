@@ -0,0 +1,68 @@
+package spil
+
+import (
+	"io/ioutil"
+	"math/big"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCall(t *testing.T) {
+	in := NewInterpreter(ioutil.Discard, getTestLibraryDir())
+	src := `
+(def sum (a:int b:int) :int (+ a b))
+(def greet (name:str) :str (do (append "hello " name) :str))
+(def twice (l:list) :list (append l (head l)))
+(def half (x:float) :float (/ x 2.0))
+(def twice-rat (x:rational) :rational (+ x x))
+(def add-one (m:map[str,int] k:str) :map[str,int] (map-put m k (+ (map-get m k 0) 1)))
+(def with-zero (s:set[int]) :set[int] (set-insert s 0))
+`
+	if err := in.Parse("__test__", strings.NewReader(src)); err != nil {
+		t.Fatalf("Parse() failed: %v", err)
+	}
+	if errs := in.Check(); len(errs) > 0 {
+		t.Fatalf("Check() failed: %v", errs)
+	}
+
+	tests := []struct {
+		fname string
+		args  []interface{}
+		exp   interface{}
+	}{
+		{"sum", []interface{}{2, 3}, int64(5)},
+		{"greet", []interface{}{"world"}, "hello world"},
+		{"twice", []interface{}{[]interface{}{1, "a"}}, []interface{}{int64(1), "a", int64(1)}},
+		{"half", []interface{}{3.0}, 1.5},
+		{"twice-rat", []interface{}{big.NewRat(2, 3)}, big.NewRat(4, 3)},
+		{"add-one", []interface{}{map[interface{}]interface{}{"a": 1, "b": 2}, "a"}, map[interface{}]interface{}{"a": int64(2), "b": int64(2)}},
+		{"with-zero", []interface{}{map[interface{}]struct{}{1: {}}}, map[interface{}]struct{}{int64(0): {}, int64(1): {}}},
+	}
+	for _, test := range tests {
+		t.Run(test.fname, func(t *testing.T) {
+			act, err := in.CallValue(test.fname, test.args...)
+			if err != nil {
+				t.Fatalf("CallValue(%v, %v) failed: %v", test.fname, test.args, err)
+			}
+			if !reflect.DeepEqual(act, test.exp) {
+				t.Errorf("Incorrect result of %v: expected %v, actual %v", test.fname, test.exp, act)
+			}
+		})
+	}
+}
+
+func TestRunArgs(t *testing.T) {
+	out := &strings.Builder{}
+	in := NewInterpreter(out, getTestLibraryDir())
+	src := `(print _1 (native.length __stdin))`
+	if err := in.Parse("__test__", strings.NewReader(src)); err != nil {
+		t.Fatalf("Parse() failed: %v", err)
+	}
+	if err := in.Run(strings.NewReader("abc"), []string{"hello"}); err != nil {
+		t.Fatalf("Run() failed: %v", err)
+	}
+	if act, exp := out.String(), "hello 3\n"; act != exp {
+		t.Errorf("Incorrect output: expected %q, actual %q", exp, act)
+	}
+}
@@ -0,0 +1,471 @@
+package spil
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Vector is an immutable indexed sequence.
+// It is stored as a persistent trie, so access to elements and modifications take O(log32 n).
+type Vector struct {
+	root  *vectorNode
+	shift uint
+	// the vector is a view of elements [start, end) of the trie
+	start, end int
+}
+
+var _ List = (*Vector)(nil)
+var _ Lenghter = (*Vector)(nil)
+var _ Nther = (*Vector)(nil)
+var _ Appender = (*Vector)(nil)
+
+const (
+	vectorBits  = 5
+	vectorWidth = 1 << vectorBits
+	vectorMask  = vectorWidth - 1
+)
+
+// vectorNode contains either sub-nodes or elements (on the lowest level of the trie).
+type vectorNode struct {
+	nodes []*vectorNode
+	items []Param
+}
+
+var EmptyVector = &Vector{}
+
+func (n *vectorNode) get(i int, shift uint) *Param {
+	for ; shift > 0; shift -= vectorBits {
+		n = n.nodes[(i>>shift)&vectorMask]
+	}
+	return &n.items[i&vectorMask]
+}
+
+// set returns copy of the node with replaced i-th element.
+func (n *vectorNode) set(i int, shift uint, p Param) *vectorNode {
+	res := &vectorNode{}
+	if shift == 0 {
+		res.items = make([]Param, vectorWidth)
+		if n != nil {
+			copy(res.items, n.items)
+		}
+		res.items[i&vectorMask] = p
+		return res
+	}
+	res.nodes = make([]*vectorNode, vectorWidth)
+	var child *vectorNode
+	if n != nil {
+		copy(res.nodes, n.nodes)
+		child = n.nodes[(i>>shift)&vectorMask]
+	}
+	res.nodes[(i>>shift)&vectorMask] = child.set(i, shift-vectorBits, p)
+	return res
+}
+
+// set returns new vector with element with absolute index i in the trie replaced.
+func (v *Vector) set(i int, p Param) *Vector {
+	root, shift := v.root, v.shift
+	for i >= 1<<(shift+vectorBits) {
+		// grow the trie
+		nodes := make([]*vectorNode, vectorWidth)
+		nodes[0] = root
+		root = &vectorNode{nodes: nodes}
+		shift += vectorBits
+	}
+	return &Vector{root: root.set(i, shift, p), shift: shift, start: v.start, end: v.end}
+}
+
+func NewVector(items ...Param) *Vector {
+	v := EmptyVector
+	for _, item := range items {
+		v = v.Push(item)
+	}
+	return v
+}
+
+func (v *Vector) Push(p Param) *Vector {
+	res := v.set(v.end, p)
+	res.end++
+	return res
+}
+
+// Assoc returns vector with replaced n-th element (elements numeration starts with 1).
+func (v *Vector) Assoc(n int, p Param) (*Vector, error) {
+	if n < 1 || n > v.Length() {
+		return nil, fmt.Errorf("Index is out of range: %v", n)
+	}
+	return v.set(v.start+n-1, p), nil
+}
+
+// Slice returns elements from 'from' to 'to' inclusively (elements numeration starts with 1).
+func (v *Vector) Slice(from, to int) (*Vector, error) {
+	if from < 1 || to > v.Length() || from > to+1 {
+		return nil, fmt.Errorf("Incorrect slice bounds: %v, %v", from, to)
+	}
+	return &Vector{root: v.root, shift: v.shift, start: v.start + from - 1, end: v.start + to}, nil
+}
+
+func (v *Vector) Length() int {
+	return v.end - v.start
+}
+
+func (v *Vector) Nth(n int) (*Param, error) {
+	if n < 1 || n > v.Length() {
+		return nil, fmt.Errorf("Index is out of range: %v", n)
+	}
+	return v.root.get(v.start+n-1, v.shift), nil
+}
+
+func (v *Vector) Head() (*Param, error) {
+	if v.Empty() {
+		return nil, fmt.Errorf("Cannot perform Head() on empty vector")
+	}
+	return v.root.get(v.start, v.shift), nil
+}
+
+func (v *Vector) Tail() (List, error) {
+	if v.Empty() {
+		return nil, fmt.Errorf("Cannot perform Tail() on empty vector")
+	}
+	return &Vector{root: v.root, shift: v.shift, start: v.start + 1, end: v.end}, nil
+}
+
+func (v *Vector) Empty() bool {
+	return v.start == v.end
+}
+
+func (v *Vector) Append(params []Param) (*Param, error) {
+	res := v
+	for _, p := range params {
+		res = res.Push(p)
+	}
+	return &Param{V: res, T: TypeVector}, nil
+}
+
+func (v *Vector) each(fn func(p *Param) error) error {
+	for i := v.start; i < v.end; i++ {
+		if err := fn(v.root.get(i, v.shift)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (v *Vector) String() string {
+	b := &strings.Builder{}
+	b.WriteString("{Vector:")
+	v.each(func(p *Param) error {
+		fmt.Fprintf(b, " %v", p)
+		return nil
+	})
+	b.WriteString("}")
+	return b.String()
+}
+
+func (v *Vector) Hash() (string, error) {
+	b := &strings.Builder{}
+	b.WriteString("{Vector:")
+	err := v.each(func(p *Param) error {
+		h, err := p.V.Hash()
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(b, " %v", h)
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	b.WriteString("}")
+	return b.String(), nil
+}
+
+func (v *Vector) Print(w io.Writer) {
+	io.WriteString(w, "[")
+	first := true
+	v.each(func(p *Param) error {
+		if !first {
+			io.WriteString(w, " ")
+		}
+		first = false
+		p.V.Print(w)
+		return nil
+	})
+	io.WriteString(w, "]")
+}
+
+func (v *Vector) Type() Type {
+	return "vector[any]"
+}
+
+// Native functions
+
+// (vector-of item1 item2 ...)
+func (in *Interpret) FVectorOf(args []Param) (*Param, error) {
+	t, err := in.VectorOfArgs(args)
+	if err != nil {
+		return nil, fmt.Errorf("FVectorOf: %w", err)
+	}
+	elem, _ := in.vectorElem(t)
+	v := EmptyVector
+	for i := range args {
+		v = v.Push(*in.promote(&args[i], elem))
+	}
+	return &Param{V: v, T: t}, nil
+}
+
+// (list->vector list)
+func (in *Interpret) FListToVector(args []Param) (*Param, error) {
+	if len(args) != 1 {
+		return nil, fmt.Errorf("FListToVector: expected 1 argument, found %v", args)
+	}
+	if v, ok := args[0].V.(*Vector); ok {
+		return &Param{V: v, T: args[0].T}, nil
+	}
+	l, ok := args[0].V.(List)
+	if !ok {
+		return nil, fmt.Errorf("FListToVector: expected argument to be List, found %v", args[0])
+	}
+	t, err := in.ListToVectorArgs(args)
+	if err != nil {
+		return nil, fmt.Errorf("FListToVector: %w", err)
+	}
+	v := EmptyVector
+	for !l.Empty() {
+		h, err := l.Head()
+		if err != nil {
+			return nil, err
+		}
+		v = v.Push(*h)
+		if l, err = l.Tail(); err != nil {
+			return nil, err
+		}
+	}
+	return &Param{V: v, T: t}, nil
+}
+
+// (vector->list vector)
+func (in *Interpret) FVectorToList(args []Param) (*Param, error) {
+	if len(args) != 1 {
+		return nil, fmt.Errorf("FVectorToList: expected 1 argument, found %v", args)
+	}
+	v, ok := args[0].V.(*Vector)
+	if !ok {
+		return nil, fmt.Errorf("FVectorToList: expected argument to be Vector, found %v", args[0])
+	}
+	t, err := in.VectorToListArgs(args)
+	if err != nil {
+		return nil, fmt.Errorf("FVectorToList: %w", err)
+	}
+	res := &Sexpr{Quoted: true, List: make([]Param, 0, v.Length())}
+	v.each(func(p *Param) error {
+		res.List = append(res.List, *p)
+		return nil
+	})
+	return &Param{V: res, T: t}, nil
+}
+
+// (vector-nth vector n)
+func (in *Interpret) FVectorNth(args []Param) (*Param, error) {
+	if len(args) != 2 {
+		return nil, fmt.Errorf("FVectorNth: expected 2 arguments, found %v", args)
+	}
+	v, n, err := vectorAndIndex("FVectorNth", args[0], args[1])
+	if err != nil {
+		return nil, err
+	}
+	return v.Nth(n)
+}
+
+// (vector-assoc vector n value)
+func (in *Interpret) FVectorAssoc(args []Param) (*Param, error) {
+	if len(args) != 3 {
+		return nil, fmt.Errorf("FVectorAssoc: expected 3 arguments, found %v", args)
+	}
+	v, n, err := vectorAndIndex("FVectorAssoc", args[0], args[1])
+	if err != nil {
+		return nil, err
+	}
+	elem, _ := in.vectorElem(args[0].T)
+	res, err := v.Assoc(n, *in.promote(&args[2], elem))
+	if err != nil {
+		return nil, err
+	}
+	return &Param{V: res, T: args[0].T}, nil
+}
+
+// (vector-push vector value)
+func (in *Interpret) FVectorPush(args []Param) (*Param, error) {
+	if len(args) != 2 {
+		return nil, fmt.Errorf("FVectorPush: expected 2 arguments, found %v", args)
+	}
+	v, ok := args[0].V.(*Vector)
+	if !ok {
+		return nil, fmt.Errorf("FVectorPush: expected first argument to be Vector, found %v", args[0])
+	}
+	elem, _ := in.vectorElem(args[0].T)
+	return &Param{V: v.Push(*in.promote(&args[1], elem)), T: args[0].T}, nil
+}
+
+// (vector-slice vector from to)
+func (in *Interpret) FVectorSlice(args []Param) (*Param, error) {
+	if len(args) != 3 {
+		return nil, fmt.Errorf("FVectorSlice: expected 3 arguments, found %v", args)
+	}
+	v, from, err := vectorAndIndex("FVectorSlice", args[0], args[1])
+	if err != nil {
+		return nil, err
+	}
+	to, ok := args[2].V.(Int)
+	if !ok {
+		return nil, fmt.Errorf("FVectorSlice: expected third argument to be Int, found %v", args[2])
+	}
+	res, err := v.Slice(from, int(to.Int64()))
+	if err != nil {
+		return nil, err
+	}
+	return &Param{V: res, T: args[0].T}, nil
+}
+
+// (vector-length vector)
+func (in *Interpret) FVectorLength(args []Param) (*Param, error) {
+	if len(args) != 1 {
+		return nil, fmt.Errorf("FVectorLength: expected 1 argument, found %v", args)
+	}
+	v, ok := args[0].V.(*Vector)
+	if !ok {
+		return nil, fmt.Errorf("FVectorLength: expected argument to be Vector, found %v", args[0])
+	}
+	return &Param{V: in.intMaker.MakeInt(int64(v.Length())), T: TypeInt}, nil
+}
+
+func vectorAndIndex(name string, vp, np Param) (*Vector, int, error) {
+	v, ok := vp.V.(*Vector)
+	if !ok {
+		return nil, 0, fmt.Errorf("%v: expected first argument to be Vector, found %v", name, vp)
+	}
+	n, ok := np.V.(Int)
+	if !ok {
+		return nil, 0, fmt.Errorf("%v: expected second argument to be Int, found %v", name, np)
+	}
+	return v, int(n.Int64()), nil
+}
+
+// Typers
+
+// vectorElem returns type of elements of the vector type.
+func (in *Interpret) vectorElem(t Type) (Type, error) {
+	return in.elemType(t, TypeVector)
+}
+
+// elemType returns type of elements of the collection type (e.g. :vector[int] -> :int).
+func (in *Interpret) elemType(t Type, collection Type) (Type, error) {
+	if t == TypeUnknown || in.IsGeneric(t) {
+		return TypeUnknown, nil
+	}
+	p, err := in.toParent(in.UnaliasType(t), collection)
+	if err != nil {
+		return TypeUnknown, fmt.Errorf("expected %v, found %v", collection, t)
+	}
+	args := p.Arguments()
+	if len(args) != 1 {
+		return TypeUnknown, fmt.Errorf("expected %v, found %v", collection, t)
+	}
+	return args[0], nil
+}
+
+// VectorOfArgs returns type of vector which contains elements of the specified types.
+func (in *Interpret) VectorOfArgs(params []Param) (Type, error) {
+	elem := TypeNothing
+	for _, p := range params {
+		elem = in.joinTypes(elem, p.T)
+	}
+	return Type("vector[" + in.typeArg(elem) + "]"), nil
+}
+
+func (in *Interpret) ListToVectorArgs(params []Param) (Type, error) {
+	if len(params) != 1 {
+		return TypeUnknown, fmt.Errorf("expected 1 argument, found %v", params)
+	}
+	elem, err := in.elemType(params[0].T, TypeList)
+	if err != nil {
+		return TypeUnknown, err
+	}
+	return Type("vector[" + in.typeArg(elem) + "]"), nil
+}
+
+func (in *Interpret) VectorToListArgs(params []Param) (Type, error) {
+	if len(params) != 1 {
+		return TypeUnknown, fmt.Errorf("expected 1 argument, found %v", params)
+	}
+	elem, err := in.vectorElem(params[0].T)
+	if err != nil {
+		return TypeUnknown, err
+	}
+	return Type("list[" + in.typeArg(elem) + "]"), nil
+}
+
+func (in *Interpret) VectorNthArgs(params []Param) (Type, error) {
+	if len(params) != 2 {
+		return TypeUnknown, fmt.Errorf("expected 2 arguments, found %v", params)
+	}
+	elem, err := in.vectorElem(params[0].T)
+	if err != nil {
+		return TypeUnknown, err
+	}
+	if err := in.expectType("index", params[1], TypeInt); err != nil {
+		return TypeUnknown, err
+	}
+	return elem, nil
+}
+
+func (in *Interpret) VectorAssocArgs(params []Param) (Type, error) {
+	if len(params) != 3 {
+		return TypeUnknown, fmt.Errorf("expected 3 arguments, found %v", params)
+	}
+	elem, err := in.VectorNthArgs(params[:2])
+	if err != nil {
+		return TypeUnknown, err
+	}
+	if err := in.expectType("value", params[2], elem); err != nil {
+		return TypeUnknown, err
+	}
+	return params[0].T, nil
+}
+
+func (in *Interpret) VectorPushArgs(params []Param) (Type, error) {
+	if len(params) != 2 {
+		return TypeUnknown, fmt.Errorf("expected 2 arguments, found %v", params)
+	}
+	elem, err := in.vectorElem(params[0].T)
+	if err != nil {
+		return TypeUnknown, err
+	}
+	if err := in.expectType("value", params[1], elem); err != nil {
+		return TypeUnknown, err
+	}
+	return params[0].T, nil
+}
+
+func (in *Interpret) VectorSliceArgs(params []Param) (Type, error) {
+	if len(params) != 3 {
+		return TypeUnknown, fmt.Errorf("expected 3 arguments, found %v", params)
+	}
+	if _, err := in.vectorElem(params[0].T); err != nil {
+		return TypeUnknown, err
+	}
+	for _, p := range params[1:] {
+		if err := in.expectType("index", p, TypeInt); err != nil {
+			return TypeUnknown, err
+		}
+	}
+	return params[0].T, nil
+}
+
+func (in *Interpret) VectorLengthArgs(params []Param) error {
+	if len(params) != 1 {
+		return fmt.Errorf("expected 1 argument, found %v", params)
+	}
+	_, err := in.vectorElem(params[0].T)
+	return err
+}
@@ -0,0 +1,108 @@
+package spil
+
+import (
+	"testing"
+)
+
+func vectorInts(t *testing.T, v *Vector) []int64 {
+	var res []int64
+	err := v.each(func(p *Param) error {
+		res = append(res, p.V.(Int).Int64())
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("each() failed: %v", err)
+	}
+	return res
+}
+
+func TestVectorPushNth(t *testing.T) {
+	const n = 5000
+	v := EmptyVector
+	for i := 0; i < n; i++ {
+		v = v.Push(intParam(i))
+	}
+	if v.Length() != n {
+		t.Fatalf("Incorrect vector length: expected %v, actual %v", n, v.Length())
+	}
+	for i := 1; i <= n; i++ {
+		p, err := v.Nth(i)
+		if err != nil {
+			t.Fatalf("Nth(%v) failed: %v", i, err)
+		}
+		if act := p.V.(Int).Int64(); act != int64(i-1) {
+			t.Errorf("Incorrect value of %v-th element: %v", i, act)
+		}
+	}
+	for _, i := range []int{0, n + 1} {
+		if _, err := v.Nth(i); err == nil {
+			t.Errorf("Nth(%v) should fail", i)
+		}
+	}
+}
+
+func TestVectorIsPersistent(t *testing.T) {
+	v := NewVector(intParam(1), intParam(2), intParam(3))
+	v2, err := v.Assoc(2, intParam(20))
+	if err != nil {
+		t.Fatalf("Assoc() failed: %v", err)
+	}
+	v3 := v.Push(intParam(4))
+	if act, exp := vectorInts(t, v), []int64{1, 2, 3}; !equalInts(act, exp) {
+		t.Errorf("Original vector changed: %v", act)
+	}
+	if act, exp := vectorInts(t, v2), []int64{1, 20, 3}; !equalInts(act, exp) {
+		t.Errorf("Incorrect result of Assoc(): expected %v, actual %v", exp, act)
+	}
+	if act, exp := vectorInts(t, v3), []int64{1, 2, 3, 4}; !equalInts(act, exp) {
+		t.Errorf("Incorrect result of Push(): expected %v, actual %v", exp, act)
+	}
+}
+
+func TestVectorSlice(t *testing.T) {
+	v := NewVector(intParam(1), intParam(2), intParam(3), intParam(4), intParam(5))
+	s, err := v.Slice(2, 4)
+	if err != nil {
+		t.Fatalf("Slice() failed: %v", err)
+	}
+	if act, exp := vectorInts(t, s), []int64{2, 3, 4}; !equalInts(act, exp) {
+		t.Errorf("Incorrect slice: expected %v, actual %v", exp, act)
+	}
+	// pushing into slice does not change the original vector
+	s = s.Push(intParam(40))
+	if act, exp := vectorInts(t, s), []int64{2, 3, 4, 40}; !equalInts(act, exp) {
+		t.Errorf("Incorrect slice after Push(): expected %v, actual %v", exp, act)
+	}
+	if act, exp := vectorInts(t, v), []int64{1, 2, 3, 4, 5}; !equalInts(act, exp) {
+		t.Errorf("Original vector changed: %v", act)
+	}
+	tail, err := s.Tail()
+	if err != nil {
+		t.Fatalf("Tail() failed: %v", err)
+	}
+	if act, exp := vectorInts(t, tail.(*Vector)), []int64{3, 4, 40}; !equalInts(act, exp) {
+		t.Errorf("Incorrect tail: expected %v, actual %v", exp, act)
+	}
+	empty, err := v.Slice(3, 2)
+	if err != nil || !empty.Empty() {
+		t.Errorf("Empty slice expected: %v, %v", empty, err)
+	}
+	if _, err := v.Slice(0, 2); err == nil {
+		t.Errorf("Slice(0, 2) should fail")
+	}
+	if _, err := v.Slice(2, 6); err == nil {
+		t.Errorf("Slice(2, 6) should fail")
+	}
+}
+
+func equalInts(a, b []int64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,2 @@
+(def f (n:int) :int (+ n 1))
+(print (f "x"))
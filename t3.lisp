@@ -0,0 +1,3 @@
+(def f (n:int) :int (if (= n 0) (native.head '()) (f (- n 1))))
+(def g (n:int) :int (+ 1 (f n)))
+(print (g 5))